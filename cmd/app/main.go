@@ -4,11 +4,15 @@ import (
 	"github.com/mini-maxit/file-storage/internal/api/services"
 	"github.com/mini-maxit/file-storage/internal/api/taskutils"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/mini-maxit/file-storage/internal/api/http/initialization"
 	"github.com/mini-maxit/file-storage/internal/api/http/server"
 	"github.com/mini-maxit/file-storage/internal/config"
+	"github.com/mini-maxit/file-storage/internal/logger"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,19 +25,41 @@ func main() {
 	}
 
 	_config := config.NewConfig()
+	logger.InitializeLogger(_config)
+
 	init := initialization.NewInitialization(_config)
 	err := init.InitializeRootDirectory()
 	if err != nil {
 		logrus.Fatalf("failed to initialize root directory: %v", err)
 	}
 
+	if _config.StorageCheck != config.StorageCheckOff && _config.StorageCheck != "" {
+		err = init.VerifyStorage(_config.StorageCheck == config.StorageCheckStrict)
+		if err != nil {
+			logrus.Fatalf("storage check failed: %v", err)
+		}
+	}
+
 	taskUtils := taskutils.NewTaskUtils(_config)
 	taskService := services.NewTaskService(_config, taskUtils)
 
 	addr := ":" + _config.Port
-	_server := server.NewServer(taskService)
+	_server := server.NewServer(_config, taskService)
+
+	// Drain in-flight requests on SIGINT/SIGTERM before exiting
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		if err := _server.ShutdownOn(signals, 30*time.Second); err != nil {
+			logrus.Errorf("failed to shut down server gracefully: %v", err)
+		}
+	}()
+
 	err = _server.Run(addr)
 	if err != nil {
 		logrus.Fatalf("server stopped: %v", err)
 	}
+	<-shutdownDone
 }
@@ -6,6 +6,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.7.0
+	github.com/ulikunitz/xz v0.5.17
 	gorm.io/gorm v1.25.12
 )
 
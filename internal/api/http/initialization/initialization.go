@@ -2,11 +2,19 @@ package initialization
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
 
+	"github.com/mini-maxit/file-storage/internal/api/taskutils"
 	"github.com/mini-maxit/file-storage/internal/config"
+	"github.com/sirupsen/logrus"
 )
 
+// taskDirPattern matches the names of the task directories.
+var taskDirPattern = regexp.MustCompile(`^task\d+$`)
+
 // Initialization is a struct that holds the application configuration.
 // It provides methods for initializing necessary components based on the configuration.
 type Initialization struct {
@@ -27,7 +35,7 @@ func (i *Initialization) InitializeRootDirectory() error {
 	// Check if the directory exists
 	if _, err := os.Stat(rootDir); os.IsNotExist(err) {
 		// Directory doesn't exist, attempt to create it
-		err := os.MkdirAll(rootDir, os.ModePerm)
+		err := os.MkdirAll(rootDir, i.config.DirPerm())
 		if err != nil {
 			// Return an error if directory creation fails
 			return fmt.Errorf("failed to create root directory %s: %v", rootDir, err)
@@ -35,3 +43,93 @@ func (i *Initialization) InitializeRootDirectory() error {
 	}
 	return nil
 }
+
+// VerifyStorage checks the on-disk structure of every task directory: the src directory must contain
+// the description.pdf file and the input/ and output/ directories with matching, sequentially numbered
+// input and output files, and every file of the task must be readable.
+// Each inconsistency is logged as a warning. In strict mode an error is returned if any inconsistency is found,
+// so that the server does not start serving from a corrupted storage.
+func (i *Initialization) VerifyStorage(strict bool) error {
+	tasksDir := i.config.TasksDirectory()
+	entries, err := os.ReadDir(tasksDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read tasks directory %s: %v", tasksDir, err)
+	}
+
+	tu := taskutils.NewTaskUtils(i.config)
+	problems := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !taskDirPattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		for _, problem := range verifyTaskDirectory(tu, filepath.Join(tasksDir, entry.Name())) {
+			logrus.Warnf("storage check: %s: %s", entry.Name(), problem)
+			problems++
+		}
+	}
+
+	if problems > 0 && strict {
+		return fmt.Errorf("storage check found %d inconsistencies in %s", problems, tasksDir)
+	}
+	return nil
+}
+
+// verifyTaskDirectory returns the inconsistencies found in a single task directory.
+func verifyTaskDirectory(tu *taskutils.TaskUtils, taskDir string) []string {
+	var problems []string
+
+	srcDir := filepath.Join(taskDir, "src")
+	if _, err := os.Stat(srcDir); err != nil {
+		return append(problems, "src directory is missing")
+	}
+
+	if _, err := os.Stat(filepath.Join(srcDir, "description.pdf")); err != nil {
+		problems = append(problems, "description.pdf is missing")
+	}
+
+	inputFiles, inputErr := tu.NumberedFiles(filepath.Join(srcDir, "input"), tu.InputPattern())
+	if inputErr != nil {
+		problems = append(problems, "input directory is missing or unreadable")
+	}
+	outputFiles, outputErr := tu.NumberedFiles(filepath.Join(srcDir, "output"), tu.OutputPattern())
+	if outputErr != nil {
+		problems = append(problems, "output directory is missing or unreadable")
+	}
+	if inputErr == nil && outputErr == nil {
+		if len(inputFiles) != len(outputFiles) {
+			problems = append(problems, fmt.Sprintf("%d input files but %d output files", len(inputFiles), len(outputFiles)))
+		}
+		for n := 1; n <= len(inputFiles); n++ {
+			if _, ok := inputFiles[n]; !ok {
+				problems = append(problems, fmt.Sprintf("input file %d is missing", n))
+			} else if _, ok := outputFiles[n]; !ok {
+				problems = append(problems, fmt.Sprintf("output file %d is missing", n))
+			}
+		}
+	}
+
+	// Ensure every file of the task, including the submissions, can be read
+	err := filepath.WalkDir(taskDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("cannot access %s: %v", path, err))
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("cannot read %s: %v", path, err))
+			return nil
+		}
+		return file.Close()
+	})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to walk task directory: %v", err))
+	}
+
+	return problems
+}
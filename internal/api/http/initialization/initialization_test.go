@@ -2,8 +2,10 @@ package initialization
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mini-maxit/file-storage/internal/api/taskutils"
 	"github.com/mini-maxit/file-storage/internal/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -83,3 +85,58 @@ func TestInitializeRootDirectoryCreateFail(t *testing.T) {
 	err := init.InitializeRootDirectory()
 	assert.Error(t, err, "expected an error when failing to create the directory")
 }
+
+// TestVerifyStorage tests the storage check of the task directories.
+func TestVerifyStorage(t *testing.T) {
+	rootDir, cleanup := setupTempDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+	init := NewInitialization(mockConfig)
+
+	// Helper function to write a file, creating its parent directories
+	writeFile := func(path string) {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		assert.NoError(t, err, "failed to create directory")
+		err = os.WriteFile(path, []byte("content"), 0644)
+		assert.NoError(t, err, "failed to create file")
+	}
+
+	tasksDir := mockConfig.TasksDirectory()
+	writeFile(filepath.Join(tasksDir, "task1", "src", "description.pdf"))
+	writeFile(filepath.Join(tasksDir, "task1", "src", "input", "1.in"))
+	writeFile(filepath.Join(tasksDir, "task1", "src", "output", "1.out"))
+
+	// Subtest for a valid storage
+	t.Run("should pass for valid task directories", func(t *testing.T) {
+		err := init.VerifyStorage(true)
+		assert.NoError(t, err, "expected no error for valid task directories")
+	})
+
+	// Broken task: missing description and a missing output file
+	writeFile(filepath.Join(tasksDir, "task2", "src", "input", "1.in"))
+	writeFile(filepath.Join(tasksDir, "task2", "src", "input", "2.in"))
+	writeFile(filepath.Join(tasksDir, "task2", "src", "output", "1.out"))
+
+	// Subtest for the default, non-fatal mode
+	t.Run("should only log inconsistencies when not strict", func(t *testing.T) {
+		err := init.VerifyStorage(false)
+		assert.NoError(t, err, "expected no error when not in strict mode")
+	})
+
+	// Subtest for the strict mode
+	t.Run("should return an error for a broken task directory in strict mode", func(t *testing.T) {
+		err := init.VerifyStorage(true)
+		assert.Error(t, err, "expected an error for a broken task directory in strict mode")
+	})
+
+	// Subtest for the problems reported for a single task
+	t.Run("should report the inconsistencies of a broken task directory", func(t *testing.T) {
+		problems := verifyTaskDirectory(taskutils.NewTaskUtils(mockConfig), filepath.Join(tasksDir, "task2"))
+		assert.Contains(t, problems, "description.pdf is missing")
+		assert.Contains(t, problems, "2 input files but 1 output files")
+		assert.Contains(t, problems, "output file 2 is missing")
+	})
+}
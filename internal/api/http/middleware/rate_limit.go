@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitExemptPaths lists the paths that are never rate limited, so that readiness checks keep working under load.
+var rateLimitExemptPaths = map[string]bool{
+	"/ready": true,
+}
+
+// bucketCleanupInterval is how often idle client buckets are dropped from memory.
+const bucketCleanupInterval = time.Minute
+
+// tokenBucket holds the available tokens of a single client.
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter limits the number of requests per client IP using a token bucket per client.
+// Each client may send up to burst requests at once, and the bucket refills at rate tokens per second.
+type RateLimiter struct {
+	rate  float64
+	burst int
+
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter allowing rate requests per second with the given burst.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:        rate,
+		burst:       burst,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Middleware rejects requests exceeding the limit with 429 Too Many Requests and a Retry-After header
+// telling the client how many seconds to wait before the next request is allowed.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rateLimitExemptPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, retryAfter := rl.allow(clientIP(r))
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			Logger(r.Context()).Warnf("rate limit exceeded for %s", clientIP(r))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the client's bucket. If the bucket is empty it returns false
+// and the time after which a token will be available.
+func (rl *RateLimiter) allow(client string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.cleanup(now)
+
+	bucket, ok := rl.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(rl.burst), lastSeen: now}
+		rl.buckets[client] = bucket
+	} else {
+		elapsed := now.Sub(bucket.lastSeen).Seconds()
+		bucket.tokens = math.Min(float64(rl.burst), bucket.tokens+elapsed*rl.rate)
+		bucket.lastSeen = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	if rl.rate <= 0 {
+		return false, bucketCleanupInterval
+	}
+	return false, time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+}
+
+// cleanup drops the buckets of clients that have been idle long enough for their bucket to be full again.
+func (rl *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(rl.lastCleanup) < bucketCleanupInterval {
+		return
+	}
+	rl.lastCleanup = now
+
+	for client, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*rl.rate >= float64(rl.burst) {
+			delete(rl.buckets, client)
+		}
+	}
+}
+
+// clientIP returns the IP address of the client that sent the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	newHandler := func() (http.Handler, *RateLimiter) {
+		rl := NewRateLimiter(1, 2)
+		return rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})), rl
+	}
+
+	sendRequest := func(handler http.Handler, path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Subtest for requests sent faster than the limit
+	t.Run("should return 429 with Retry-After when the limit is exceeded", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		for i := 0; i < 2; i++ {
+			rec := sendRequest(handler, "/getTaskFiles", "192.0.2.1:1234")
+			assert.Equal(t, http.StatusOK, rec.Code, "expected requests within the burst to be allowed")
+		}
+
+		rec := sendRequest(handler, "/getTaskFiles", "192.0.2.1:1234")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, "expected 429 when the limit is exceeded")
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"), "expected the client to be told to retry after one second")
+	})
+
+	// Subtest for limiting each client separately
+	t.Run("should limit each client IP separately", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		for i := 0; i < 3; i++ {
+			sendRequest(handler, "/getTaskFiles", "192.0.2.1:1234")
+		}
+
+		rec := sendRequest(handler, "/getTaskFiles", "192.0.2.2:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "expected another client not to be limited")
+	})
+
+	// Subtest for refilling the bucket over time
+	t.Run("should allow requests again after the bucket refills", func(t *testing.T) {
+		handler, rl := newHandler()
+		now := time.Now()
+		rl.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			sendRequest(handler, "/getTaskFiles", "192.0.2.1:1234")
+		}
+
+		now = now.Add(time.Second)
+		rec := sendRequest(handler, "/getTaskFiles", "192.0.2.1:1234")
+		assert.Equal(t, http.StatusOK, rec.Code, "expected a request to be allowed after the bucket refilled")
+	})
+
+	// Subtest for the readiness check exemption
+	t.Run("should not limit the ready endpoint", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		for i := 0; i < 5; i++ {
+			rec := sendRequest(handler, "/ready", "192.0.2.1:1234")
+			assert.Equal(t, http.StatusOK, rec.Code, "expected the ready endpoint not to be limited")
+		}
+	})
+}
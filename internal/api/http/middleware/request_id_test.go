@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var seenID string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenID = RequestIDFromContext(r.Context())
+	}))
+
+	// Subtest for echoing a request ID provided by the client
+	t.Run("should echo a provided request ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/getTaskFiles", nil)
+		req.Header.Set(RequestIDHeader, "test-request-id")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "test-request-id", rec.Header().Get(RequestIDHeader), "expected the request ID to be echoed back")
+		assert.Equal(t, "test-request-id", seenID, "expected the request ID to be stored in the context")
+	})
+
+	// Subtest for generating a request ID when none is provided
+	t.Run("should generate a request ID when none is provided", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/getTaskFiles", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		generatedID := rec.Header().Get(RequestIDHeader)
+		assert.Len(t, generatedID, 36, "expected a UUID to be generated")
+		assert.Equal(t, generatedID, seenID, "expected the generated ID to be stored in the context")
+	})
+}
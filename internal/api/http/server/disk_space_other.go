@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd)
+
+package server
+
+// freeDiskSpace is not supported on this platform, the readiness check skips the free space check.
+func freeDiskSpace(path string) (uint64, error) {
+	return 0, errDiskSpaceUnsupported
+}
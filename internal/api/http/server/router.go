@@ -1,32 +1,145 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"mime"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
+	"github.com/mini-maxit/file-storage/internal/api/http/middleware"
 	"github.com/mini-maxit/file-storage/internal/api/services"
+	"github.com/mini-maxit/file-storage/internal/config"
 	"github.com/mini-maxit/file-storage/utils"
 	"github.com/sirupsen/logrus"
 )
 
+// errDiskSpaceUnsupported is returned by freeDiskSpace on platforms where the free space cannot be determined.
+var errDiskSpaceUnsupported = errors.New("free disk space check is not supported on this platform")
+
+// checkFreeDiskSpace returns the free disk space under the given path, it is replaced in tests.
+var checkFreeDiskSpace = freeDiskSpace
+
+// readHeaderTimeout bounds the time a client may take to send the request headers, so that Shutdown
+// is not held up by connections that never finish them.
+var readHeaderTimeout = 10 * time.Second
+
+// supportedArchiveFormats lists the archive formats accepted by the upload endpoints.
+var supportedArchiveFormats = []string{".zip", ".tar.gz", ".tar.bz2", ".tar.xz"}
+
 type Server struct {
-	mux http.Handler
+	mux    http.Handler
+	server *http.Server
 }
 
+// Run listens on the given address and serves requests until the server is shut down.
 func (s *Server) Run(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
 	logrus.Infof("Server is running on %s", addr)
-	return http.ListenAndServe(addr, s.mux)
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the given listener until the server is shut down.
+// It returns nil when the server stopped because of a call to Shutdown.
+func (s *Server) Serve(listener net.Listener) error {
+	err := s.server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully stops the server, waiting for in-flight requests to complete
+// until the given context is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	logrus.Info("Shutting down server")
+	return s.server.Shutdown(ctx)
+}
+
+// ShutdownOn waits for a signal on signals and then gracefully stops the server,
+// giving in-flight requests up to timeout to complete.
+func (s *Server) ShutdownOn(signals <-chan os.Signal, timeout time.Duration) error {
+	<-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.Shutdown(ctx)
 }
 
-func NewServer(ts *services.TaskService) *Server {
+func NewServer(cfg *config.Config, ts *services.TaskService) *Server {
 	mux := http.NewServeMux()
 
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeStatus := func(status int, reason string) {
+			response := map[string]interface{}{"status": "ready"}
+			if status != http.StatusOK {
+				response = map[string]interface{}{"status": "not ready", "reason": reason}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				middleware.Logger(r.Context()).Errorf("failed to encode readiness response: %v", err)
+			}
+		}
+
+		// The storage has to be reachable to serve any request
+		if _, err := os.Stat(cfg.RootDirectory); err != nil {
+			writeStatus(http.StatusServiceUnavailable, "root directory is not accessible")
+			return
+		}
+
+		// Stop receiving uploads that could not be written
+		if cfg.MinFreeDiskSpace > 0 {
+			free, err := checkFreeDiskSpace(cfg.RootDirectory)
+			if err != nil && !errors.Is(err, errDiskSpaceUnsupported) {
+				middleware.Logger(r.Context()).Errorf("failed to check free disk space: %v", err)
+				writeStatus(http.StatusServiceUnavailable, "failed to check free disk space")
+				return
+			}
+			if err == nil && free < uint64(cfg.MinFreeDiskSpace) {
+				writeStatus(http.StatusServiceUnavailable, fmt.Sprintf("only %d bytes of disk space left, %d required", free, cfg.MinFreeDiskSpace))
+				return
+			}
+		}
+
+		writeStatus(http.StatusOK, "")
+	})
+
+	mux.HandleFunc("/getConfig", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Expose only the limits clients need to validate uploads, never paths or secrets
+		response := map[string]interface{}{
+			"allowedFileTypes":   cfg.AllowedFileTypes,
+			"archiveFormats":     supportedArchiveFormats,
+			"maxTaskArchiveSize": cfg.TaskArchiveLimit(),
+			"maxUploadSize":      cfg.UploadLimit(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	})
+
 	mux.HandleFunc("/createTask", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -34,11 +147,10 @@ func NewServer(ts *services.TaskService) *Server {
 		}
 
 		// Limit the size of the incoming request
-		r.Body = http.MaxBytesReader(w, r.Body, 50*1024*1024) // 50 MB limit
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.TaskArchiveLimit())
 
 		// Parse the multipart form data
-		if err := r.ParseMultipartForm(50 << 20); err != nil {
-			http.Error(w, "The uploaded files are too large.", http.StatusBadRequest)
+		if !parseMultipartForm(w, r, cfg.TaskArchiveLimit(), "The uploaded files are too large.") {
 			return
 		}
 
@@ -65,107 +177,58 @@ func NewServer(ts *services.TaskService) *Server {
 			}
 		}
 
-		// Process the uploaded archive
-		archiveFile, fileHeader, err := r.FormFile("archive")
-		if err != nil {
-			http.Error(w, "Archive file is required.", http.StatusBadRequest)
-			return
-		}
-		defer utils.CloseIO(archiveFile)
-
-		// Save the archive temporarily
-		originalExt := filepath.Ext(fileHeader.Filename)
-		tempArchivePath := filepath.Join(os.TempDir(), fmt.Sprintf("task_archive_%d%s", taskID, originalExt))
-		tempArchive, err := os.Create(tempArchivePath)
-		if err != nil {
-			http.Error(w, "Failed to create temporary file for archive.", http.StatusInternalServerError)
+		// Save the uploaded archive, the service decompresses it without loading the files into memory
+		archivePath, ok := saveTaskArchive(w, r)
+		if !ok {
 			return
 		}
-		defer utils.RemoveDirectory(tempArchivePath)
-		defer utils.CloseIO(tempArchive)
+		defer utils.RemoveDirectory(archivePath)
 
-		if _, err := io.Copy(tempArchive, archiveFile); err != nil {
-			http.Error(w, "Failed to save archive file.", http.StatusInternalServerError)
-			return
-		}
-
-		// Decompress the archive to a temporary directory
-		tempExtractPath := filepath.Join(os.TempDir(), fmt.Sprintf("task_%d", taskID))
-		defer utils.RemoveDirectory(tempExtractPath)
-
-		if err := utils.DecompressArchive(tempArchivePath, tempExtractPath); err != nil {
-			http.Error(w, "Failed to decompress archive.", http.StatusInternalServerError)
+		// Invoke the service function
+		serviceErr := ts.CreateTaskDirectoryFromArchive(taskID, archivePath, overwrite)
+		if serviceErr != nil {
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to create Task Directory", map[string]interface{}{
+				"taskID":    taskID,
+				"overwrite": overwrite,
+			})
 			return
 		}
-		entries, err := os.ReadDir(tempExtractPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if len(entries) != 1 {
-			http.Error(w, "Task archive has to contain exactly 1 main folder", http.StatusBadRequest)
-		}
-
-		extractedPath := filepath.Join(tempExtractPath, entries[0].Name())
 
-		// Prepare files map from decompressed folder structure
-		filesMap := make(map[string][]byte)
-
-		// Load description file
-		descriptionPath := filepath.Join(extractedPath, "description.pdf")
-		descriptionContent, err := os.ReadFile(descriptionPath)
+		_, err = w.Write([]byte("Task directory created successfully"))
 		if err != nil {
-			http.Error(w, "Description file is missing or unreadable in the archive.", http.StatusBadRequest)
 			return
 		}
-		filesMap["src/description.pdf"] = descriptionContent
+	})
 
-		// Load input files
-		inputDir := filepath.Join(extractedPath, "input")
-		inputFiles, err := os.ReadDir(inputDir)
-		if err != nil {
-			http.Error(w, "Input directory is missing in the archive.", http.StatusBadRequest)
+	mux.HandleFunc("/validateTask", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		for _, file := range inputFiles {
-			filePath := filepath.Join(inputDir, file.Name())
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				http.Error(w, "Failed to read input file in the archive.", http.StatusInternalServerError)
-				return
-			}
-			filesMap["src/input/"+file.Name()] = content
-		}
+		// Limit the size of the incoming request
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.TaskArchiveLimit())
 
-		// Load output files
-		outputDir := filepath.Join(extractedPath, "output")
-		outputFiles, err := os.ReadDir(outputDir)
-		if err != nil {
-			http.Error(w, "Output directory is missing in the archive.", http.StatusBadRequest)
+		// Parse the multipart form data
+		if !parseMultipartForm(w, r, cfg.TaskArchiveLimit(), "The uploaded files are too large.") {
 			return
 		}
 
-		for _, file := range outputFiles {
-			filePath := filepath.Join(outputDir, file.Name())
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				http.Error(w, "Failed to read output file in the archive.", http.StatusInternalServerError)
-				return
-			}
-			filesMap["src/output/"+file.Name()] = content
+		// Save the uploaded archive, the service decompresses it without loading the files into memory
+		archivePath, ok := saveTaskArchive(w, r)
+		if !ok {
+			return
 		}
+		defer utils.RemoveDirectory(archivePath)
 
-		// Invoke the service function
-		serviceErr := ts.CreateTaskDirectory(taskID, filesMap, overwrite)
+		// Validate the archive with the same rules as /createTask, without creating the task directory
+		serviceErr := ts.ValidateTaskArchive(archivePath)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to create Task Directory", map[string]interface{}{
-				"taskID":    taskID,
-				"overwrite": overwrite,
-			})
+			services.WriteServiceError(r.Context(), serviceErr, w, "Task files are invalid", nil)
 			return
 		}
 
-		_, err = w.Write([]byte("Task directory created successfully"))
+		_, err := w.Write([]byte("Task files are valid"))
 		if err != nil {
 			return
 		}
@@ -177,12 +240,11 @@ func NewServer(ts *services.TaskService) *Server {
 			return
 		}
 
-		// Limit the size of the incoming request to 10 MB
-		r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024)
+		// Limit the size of the incoming request
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.UploadLimit())
 
 		// Parse the multipart form data
-		if err := r.ParseMultipartForm(10 << 20); err != nil {
-			http.Error(w, "The uploaded file is too large.", http.StatusBadRequest)
+		if !parseMultipartForm(w, r, cfg.UploadLimit(), "The uploaded file is too large.") {
 			return
 		}
 
@@ -224,7 +286,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Invoke the service function to handle the submission
 		submissionNumber, serviceErr := ts.CreateUserSubmission(taskID, userID, fileContent, fileHeader.Filename)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to create User Submission", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to create User Submission", map[string]interface{}{
 				"taskID":   taskID,
 				"userID":   userID,
 				"fileName": fileHeader.Filename,
@@ -249,12 +311,11 @@ func NewServer(ts *services.TaskService) *Server {
 			return
 		}
 
-		// Limit the size of the incoming request to 10 MB
-		r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024)
+		// Limit the size of the incoming request
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.UploadLimit())
 
 		// Parse the multipart form data
-		if err := r.ParseMultipartForm(10 << 20); err != nil {
-			http.Error(w, "The uploaded files are too large.", http.StatusBadRequest)
+		if !parseMultipartForm(w, r, cfg.UploadLimit(), "The uploaded files are too large.") {
 			return
 		}
 
@@ -285,6 +346,17 @@ func NewServer(ts *services.TaskService) *Server {
 			return
 		}
 
+		// Extract 'overwrite' flag from form data
+		overwriteStr := r.FormValue("overwrite")
+		overwrite := false
+		if overwriteStr != "" {
+			overwrite, err = strconv.ParseBool(overwriteStr)
+			if err != nil {
+				http.Error(w, "Invalid overwrite flag.", http.StatusBadRequest)
+				return
+			}
+		}
+
 		// Prepare maps for output files and error file
 		outputFiles := make(map[string][]byte)
 
@@ -296,14 +368,14 @@ func NewServer(ts *services.TaskService) *Server {
 		}
 		defer utils.CloseIO(archiveFile)
 
-		// Save the archive temporarily
+		// Save the archive temporarily, in a file of its own so concurrent uploads for the same task do not collide
 		originalExt := filepath.Ext(fileHeader.Filename)
-		tempArchivePath := filepath.Join(os.TempDir(), fmt.Sprintf("outputs_archive_%d%s", taskID, originalExt))
-		tempArchive, err := os.Create(tempArchivePath)
+		tempArchive, err := os.CreateTemp("", fmt.Sprintf("outputs_archive_%d_*%s", taskID, originalExt))
 		if err != nil {
 			http.Error(w, "Failed to create temporary file for archive.", http.StatusInternalServerError)
 			return
 		}
+		tempArchivePath := tempArchive.Name()
 		defer utils.RemoveDirectory(tempArchivePath)
 		defer utils.CloseIO(tempArchive)
 
@@ -313,7 +385,11 @@ func NewServer(ts *services.TaskService) *Server {
 		}
 
 		// Decompress the archive to a temporary directory
-		tempExtractPath := filepath.Join(os.TempDir(), fmt.Sprintf("task_outputs_%d", taskID))
+		tempExtractPath, err := os.MkdirTemp("", fmt.Sprintf("task_outputs_%d_*", taskID))
+		if err != nil {
+			http.Error(w, "Failed to create temporary directory for archive.", http.StatusInternalServerError)
+			return
+		}
 		defer utils.RemoveDirectory(tempExtractPath)
 
 		if err := utils.DecompressArchive(tempArchivePath, tempExtractPath); err != nil {
@@ -340,12 +416,13 @@ func NewServer(ts *services.TaskService) *Server {
 		}
 
 		// Store the output files in the service function
-		serviceErr := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles)
+		serviceErr := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles, overwrite)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to store user outputs", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to store user outputs", map[string]interface{}{
 				"taskID":     taskID,
 				"userID":     userID,
 				"submission": submissionNumberStr,
+				"overwrite":  overwrite,
 			})
 			return
 		}
@@ -378,7 +455,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Call GetTaskFiles to retrieve the task files as a .tar.gz archive
 		tarFilePath, serviceErr := ts.GetTaskFiles(taskID)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to get task files", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to get task files", map[string]interface{}{
 				"taskID": taskID,
 			})
 			return
@@ -455,7 +532,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Retrieve the user's submission file content
 		fileContent, fileName, serviceErr := ts.GetUserSubmission(taskID, userID, submissionNumber)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to get user submission files", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to get user submission files", map[string]interface{}{
 				"taskID":     taskID,
 				"userID":     userID,
 				"submission": submissionNumberStr,
@@ -464,7 +541,7 @@ func NewServer(ts *services.TaskService) *Server {
 		}
 
 		// Set response headers to prompt file download with the original file name
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+		w.Header().Set("Content-Disposition", attachmentDisposition(fileName))
 		w.Header().Set("Content-Type", "application/octet-stream")
 		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileContent)))
 
@@ -510,7 +587,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Call GetTaskFiles to retrieve the task files as a .tar.gz archive
 		tarFilePath, serviceErr := ts.GetInputOutput(taskID, inputOutputID)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to get input output files", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to get input output files", map[string]interface{}{
 				"taskID":        taskID,
 				"inputOutputID": inputOutputID,
 			})
@@ -583,7 +660,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Call GetUserSolutionPackage to retrieve the package as a .tar.gz archive
 		tarFilePath, serviceErr := ts.GetUserSolutionPackage(taskID, userID, submissionNum)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to get user submission files", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to get user submission files", map[string]interface{}{
 				"taskID":        taskID,
 				"userID":        userID,
 				"submissionNum": submissionNum,
@@ -637,7 +714,7 @@ func NewServer(ts *services.TaskService) *Server {
 		// Call DeleteTask to delete the specified task directory
 		serviceErr := ts.DeleteTask(taskID)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to delete task", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to delete task", map[string]interface{}{
 				"taskID": taskID,
 			})
 			return
@@ -672,26 +749,124 @@ func NewServer(ts *services.TaskService) *Server {
 			return
 		}
 
-		// Retrieve the task description file content
-		fileContent, fileName, serviceErr := ts.GetTaskDescription(taskID)
+		// Open the task description file, streaming it so large descriptions are not buffered in memory
+		description, fileName, serviceErr := ts.GetTaskDescriptionStream(taskID)
 		if serviceErr != nil {
-			services.WriteServiceError(serviceErr, w, "Failed to get task description file", map[string]interface{}{
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to get task description file", map[string]interface{}{
 				"taskID": taskID,
 			})
 			return
 		}
+		defer utils.CloseIO(description)
 
 		// Set response headers to prompt file download with the original file name
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+		w.Header().Set("Content-Disposition", attachmentDisposition(fileName))
 		w.Header().Set("Content-Type", "application/pdf")
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileContent)))
+		if file, ok := description.(*os.File); ok {
+			if info, err := file.Stat(); err == nil {
+				w.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size()))
+			}
+		}
 
-		// Write file content to the response
-		if _, err := w.Write(fileContent); err != nil {
+		// Stream the file content to the response
+		if _, err := io.Copy(w, description); err != nil {
 			http.Error(w, "Failed to write file content to response", http.StatusInternalServerError)
 			return
 		}
 	})
 
-	return &Server{mux: mux}
+	mux.HandleFunc("/listUserSubmissions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Extract 'userID' from query parameters
+		userIDStr := r.URL.Query().Get("userID")
+		if userIDStr == "" {
+			http.Error(w, "userID is required.", http.StatusBadRequest)
+			return
+		}
+
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid userID.", http.StatusBadRequest)
+			return
+		}
+
+		// Collect the user's submissions across all tasks
+		submissions, serviceErr := ts.ListAllUserSubmissions(userID)
+		if serviceErr != nil {
+			services.WriteServiceError(r.Context(), serviceErr, w, "Failed to list user submissions", map[string]interface{}{
+				"userID": userID,
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(submissions); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	})
+
+	var handler http.Handler = mux
+	if cfg.RateLimit > 0 {
+		handler = middleware.NewRateLimiter(cfg.RateLimit, cfg.RateBurst).Middleware(handler)
+	}
+	handler = middleware.RequestID(handler)
+	return &Server{
+		mux:    handler,
+		server: &http.Server{Handler: handler, ReadHeaderTimeout: readHeaderTimeout},
+	}
+}
+
+// attachmentDisposition returns the Content-Disposition header prompting the download of a file with the given name.
+// The name is quoted or encoded as needed, as it may be the original name of a submitted file.
+func attachmentDisposition(fileName string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+}
+
+// parseMultipartForm parses the multipart form of a request whose body is limited with http.MaxBytesReader.
+// It responds with 413 and the given message if the body exceeds the limit, or with 400 if the form is malformed,
+// and returns whether the form was parsed.
+func parseMultipartForm(w http.ResponseWriter, r *http.Request, limit int64, tooLargeMessage string) bool {
+	err := r.ParseMultipartForm(limit)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, tooLargeMessage, http.StatusRequestEntityTooLarge)
+	} else {
+		http.Error(w, "Invalid multipart form.", http.StatusBadRequest)
+	}
+	return false
+}
+
+// saveTaskArchive saves the uploaded task archive to a temporary file keeping its extension, and returns its path.
+// The caller is responsible for removing the file.
+func saveTaskArchive(w http.ResponseWriter, r *http.Request) (string, bool) {
+	archiveFile, fileHeader, err := r.FormFile("archive")
+	if err != nil {
+		http.Error(w, "Archive file is required.", http.StatusBadRequest)
+		return "", false
+	}
+	defer utils.CloseIO(archiveFile)
+
+	originalExt := filepath.Ext(fileHeader.Filename)
+	tempArchive, err := os.CreateTemp("", "task_archive_*"+originalExt)
+	if err != nil {
+		http.Error(w, "Failed to create temporary file for archive.", http.StatusInternalServerError)
+		return "", false
+	}
+	defer utils.CloseIO(tempArchive)
+
+	if _, err := io.Copy(tempArchive, archiveFile); err != nil {
+		utils.RemoveDirectory(tempArchive.Name())
+		http.Error(w, "Failed to save archive file.", http.StatusInternalServerError)
+		return "", false
+	}
+
+	return tempArchive.Name(), true
 }
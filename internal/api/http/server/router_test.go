@@ -0,0 +1,366 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mini-maxit/file-storage/internal/api/http/middleware"
+	"github.com/mini-maxit/file-storage/internal/api/services"
+	"github.com/mini-maxit/file-storage/internal/api/taskutils"
+	"github.com/mini-maxit/file-storage/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer creates a Server backed by a TaskService rooted in a temporary directory.
+func newTestServer(t *testing.T) (*Server, *config.Config) {
+	t.Helper()
+
+	cfg := &config.Config{
+		RootDirectory:    t.TempDir(),
+		AllowedFileTypes: []string{".c", ".cpp", ".py"},
+	}
+	tu := taskutils.NewTaskUtils(cfg)
+	ts := services.NewTaskService(cfg, tu)
+	return NewServer(cfg, ts), cfg
+}
+
+func TestGetConfig(t *testing.T) {
+	s, cfg := newTestServer(t)
+
+	// Subtest for returning the server limits
+	t.Run("should return the upload limits and allowed file types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/getConfig", nil)
+		rec := httptest.NewRecorder()
+
+		s.mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, "expected 200 OK")
+
+		var response map[string]interface{}
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err, "expected a JSON response")
+
+		assert.ElementsMatch(t, []interface{}{".c", ".cpp", ".py"}, response["allowedFileTypes"], "expected the allowed file types")
+		assert.EqualValues(t, config.DefaultMaxUploadSize, response["maxUploadSize"], "expected the max upload size")
+		assert.EqualValues(t, config.DefaultMaxTaskArchiveSize, response["maxTaskArchiveSize"], "expected the max task archive size")
+		assert.NotContains(t, rec.Body.String(), cfg.RootDirectory, "expected the root directory not to be exposed")
+	})
+
+	// Subtest for rejecting other methods
+	t.Run("should reject non-GET requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/getConfig", nil)
+		rec := httptest.NewRecorder()
+
+		s.mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "expected 405 Method Not Allowed")
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	// Replace the free disk space check so that /ready blocks until it is released
+	var requestStarted, releaseRequest chan struct{}
+	originalCheck := checkFreeDiskSpace
+	checkFreeDiskSpace = func(path string) (uint64, error) {
+		close(requestStarted)
+		<-releaseRequest
+		return 1 << 30, nil
+	}
+	defer func() { checkFreeDiskSpace = originalCheck }()
+
+	// Subtest for letting an in-flight request complete during shutdown
+	t.Run("should complete in-flight requests before shutting down on a signal", func(t *testing.T) {
+		requestStarted = make(chan struct{})
+		releaseRequest = make(chan struct{})
+
+		s, cfg := newTestServer(t)
+		cfg.MinFreeDiskSpace = 1
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err, "expected no error creating listener")
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- s.Serve(listener)
+		}()
+
+		// Issue a request that blocks inside the handler
+		type result struct {
+			status int
+			err    error
+		}
+		responses := make(chan result, 1)
+		go func() {
+			resp, err := http.Get("http://" + listener.Addr().String() + "/ready")
+			if err != nil {
+				responses <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			_, err = io.ReadAll(resp.Body)
+			responses <- result{status: resp.StatusCode, err: err}
+		}()
+		<-requestStarted
+
+		// Send a shutdown signal while the request is still in flight
+		signals := make(chan os.Signal, 1)
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- s.ShutdownOn(signals, 5*time.Second)
+		}()
+		signals <- os.Interrupt
+
+		// Give Shutdown a moment to close the listener, then let the request finish
+		time.Sleep(50 * time.Millisecond)
+		close(releaseRequest)
+
+		res := <-responses
+		assert.NoError(t, res.err, "expected the in-flight request to succeed")
+		assert.Equal(t, http.StatusOK, res.status, "expected the in-flight request to be answered")
+		assert.NoError(t, <-shutdownErr, "expected graceful shutdown to succeed")
+		assert.NoError(t, <-serveErr, "expected Serve to return nil after shutdown")
+	})
+
+	// Subtest for a client that never finishes sending its headers
+	t.Run("should not wait for connections that never finish their headers", func(t *testing.T) {
+		defer func(timeout time.Duration) {
+			readHeaderTimeout = timeout
+		}(readHeaderTimeout)
+		readHeaderTimeout = 100 * time.Millisecond
+
+		s, _ := newTestServer(t)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err, "expected no error creating listener")
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- s.Serve(listener)
+		}()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		assert.NoError(t, err, "expected no error connecting to the server")
+		defer conn.Close()
+		_, err = conn.Write([]byte("GET /ready HTTP/1.1\r\nHost: localhost\r\n"))
+		assert.NoError(t, err, "expected no error sending the partial headers")
+		time.Sleep(50 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		assert.NoError(t, s.Shutdown(ctx), "expected shutdown not to be held up by the incomplete request")
+		assert.NoError(t, <-serveErr, "expected Serve to return nil after shutdown")
+	})
+}
+
+func TestDeleteTaskHandler(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	// Subtest for a missing task
+	t.Run("should return 404 when the task does not exist", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/deleteTask?taskID=42", nil)
+		rec := httptest.NewRecorder()
+
+		s.mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "expected 404 Not Found for a missing task")
+
+		var response map[string]interface{}
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err, "expected a JSON error response")
+		assert.Equal(t, services.ErrInvalidTaskID.Error(), response["details"], "expected the not found reason in the details")
+	})
+
+	// Subtest for logging the error with the request ID
+	t.Run("should log the error with the request ID", func(t *testing.T) {
+		var logs bytes.Buffer
+		previousOutput := logrus.StandardLogger().Out
+		logrus.SetOutput(&logs)
+		defer logrus.SetOutput(previousOutput)
+
+		req := httptest.NewRequest(http.MethodDelete, "/deleteTask?taskID=42", nil)
+		req.Header.Set(middleware.RequestIDHeader, "delete-task-request")
+		rec := httptest.NewRecorder()
+
+		s.mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "expected 404 Not Found for a missing task")
+		assert.Contains(t, logs.String(), "request_id=delete-task-request", "expected the error log to include the request ID")
+		assert.Contains(t, logs.String(), services.ErrInvalidTaskID.Error(), "expected the error log to include the error")
+	})
+}
+
+func TestStoreOutputsHandler(t *testing.T) {
+	s, cfg := newTestServer(t)
+	ts := services.NewTaskService(cfg, taskutils.NewTaskUtils(cfg))
+
+	err := ts.CreateTaskDirectory(1, map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input 1 content"),
+		"src/output/1.out":    []byte("Output 1 content"),
+	}, false)
+	assert.NoError(t, err, "expected no error when creating the task directory")
+
+	// Helper function to build a request storing the given output of a user's first submission
+	newStoreOutputsRequest := func(userID int, output string) *http.Request {
+		archive := &bytes.Buffer{}
+		gzipWriter := gzip.NewWriter(archive)
+		tarWriter := tar.NewWriter(gzipWriter)
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "user-output/1.out", Mode: 0644, Size: int64(len(output))}), "failed to write tar header")
+		_, err := tarWriter.Write([]byte(output))
+		assert.NoError(t, err, "failed to write tar entry")
+		assert.NoError(t, tarWriter.Close(), "failed to close tar writer")
+		assert.NoError(t, gzipWriter.Close(), "failed to close gzip writer")
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		_ = writer.WriteField("taskID", "1")
+		_ = writer.WriteField("userID", fmt.Sprint(userID))
+		_ = writer.WriteField("submissionNumber", "1")
+		_ = writer.WriteField("overwrite", "true")
+		part, err := writer.CreateFormFile("archive", "outputs.tar.gz")
+		assert.NoError(t, err, "failed to create form file")
+		_, err = part.Write(archive.Bytes())
+		assert.NoError(t, err, "failed to write form file")
+		assert.NoError(t, writer.Close(), "failed to close multipart writer")
+
+		req := httptest.NewRequest(http.MethodPost, "/storeOutputs", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	// Subtest for several users storing outputs of the same task at once
+	t.Run("should store concurrent outputs of the same task separately", func(t *testing.T) {
+		const users = 4
+		for userID := 1; userID <= users; userID++ {
+			_, serviceErr := ts.CreateUserSubmission(1, userID, []byte("int main() { return 0; }"), "solution.c")
+			assert.NoError(t, serviceErr, "expected no error when creating a submission")
+		}
+
+		var wg sync.WaitGroup
+		codes := make([]int, users+1)
+		for userID := 1; userID <= users; userID++ {
+			wg.Add(1)
+			go func(userID int) {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				s.mux.ServeHTTP(rec, newStoreOutputsRequest(userID, fmt.Sprintf("Output of user %d", userID)))
+				codes[userID] = rec.Code
+			}(userID)
+		}
+		wg.Wait()
+
+		for userID := 1; userID <= users; userID++ {
+			assert.Equal(t, http.StatusOK, codes[userID], "expected the outputs of user %d to be stored", userID)
+			stored, readErr := os.ReadFile(filepath.Join(cfg.TasksDirectory(), "task1", "submissions", fmt.Sprintf("user%d", userID), "submission1", "output", "1.out"))
+			assert.NoError(t, readErr, "expected the output of user %d to exist", userID)
+			assert.Equal(t, fmt.Sprintf("Output of user %d", userID), string(stored), "expected the output of user %d", userID)
+		}
+	})
+}
+
+func TestAttachmentDisposition(t *testing.T) {
+	// Subtest for file names that would break an unquoted header
+	for _, fileName := range []string{"solution.c", "Main; size=1.java", `say "hi".py`, "rozwiązanie.cpp"} {
+		t.Run("should keep the file name "+fileName, func(t *testing.T) {
+			disposition, params, err := mime.ParseMediaType(attachmentDisposition(fileName))
+			assert.NoError(t, err, "expected a valid Content-Disposition header")
+			assert.Equal(t, "attachment", disposition, "expected an attachment")
+			assert.Equal(t, map[string]string{"filename": fileName}, params, "expected only the file name parameter")
+		})
+	}
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	s, cfg := newTestServer(t)
+	cfg.MaxUploadSize = 1 << 10
+
+	// Helper function to build a submission request with a file of the given size
+	newSubmitRequest := func(size int) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		_ = writer.WriteField("taskID", "1")
+		_ = writer.WriteField("userID", "1")
+		part, err := writer.CreateFormFile("submissionFile", "solution.c")
+		assert.NoError(t, err, "failed to create form file")
+		_, err = part.Write(bytes.Repeat([]byte("a"), size))
+		assert.NoError(t, err, "failed to write form file")
+		assert.NoError(t, writer.Close(), "failed to close multipart writer")
+
+		req := httptest.NewRequest(http.MethodPost, "/submit", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	// Subtest for a body over the configured limit
+	t.Run("should return 413 when the body exceeds the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, newSubmitRequest(4<<10))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, "expected 413 for a body over the limit")
+	})
+
+	// Subtest for a body within the configured limit
+	t.Run("should accept a body within the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, newSubmitRequest(100))
+		assert.NotEqual(t, http.StatusRequestEntityTooLarge, rec.Code, "expected a body within the limit to be accepted")
+		assert.Equal(t, http.StatusNotFound, rec.Code, "expected the request to reach the service, which reports the missing task")
+	})
+}
+
+func TestReady(t *testing.T) {
+	s, cfg := newTestServer(t)
+	cfg.MinFreeDiskSpace = 100 << 20
+
+	// Replace the free disk space check with a mock
+	var freeSpace uint64
+	originalCheck := checkFreeDiskSpace
+	checkFreeDiskSpace = func(path string) (uint64, error) {
+		return freeSpace, nil
+	}
+	defer func() { checkFreeDiskSpace = originalCheck }()
+
+	// Subtest for enough free disk space
+	t.Run("should return 200 when there is enough free disk space", func(t *testing.T) {
+		freeSpace = 1 << 30
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "expected the service to be ready")
+	})
+
+	// Subtest for low free disk space
+	t.Run("should return 503 when the free disk space is below the threshold", func(t *testing.T) {
+		freeSpace = 10 << 20
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "expected the service not to be ready")
+
+		var response map[string]interface{}
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err, "expected a JSON response")
+		assert.Equal(t, "not ready", response["status"], "expected the not ready status")
+	})
+
+	// Subtest for an inaccessible root directory
+	t.Run("should return 503 when the root directory is not accessible", func(t *testing.T) {
+		freeSpace = 1 << 30
+		rootDirectory := cfg.RootDirectory
+		cfg.RootDirectory = filepath.Join(rootDirectory, "missing")
+		defer func() { cfg.RootDirectory = rootDirectory }()
+
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "expected the service not to be ready")
+	})
+}
@@ -2,14 +2,18 @@ package services
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mini-maxit/file-storage/internal/api/taskutils"
 	"github.com/mini-maxit/file-storage/utils"
@@ -17,6 +21,28 @@ import (
 	"github.com/mini-maxit/file-storage/internal/config"
 )
 
+// UserSubmission describes a single submission of a user for a task.
+type UserSubmission struct {
+	TaskID           int       `json:"taskID"`
+	SubmissionNumber int       `json:"submissionNumber"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// submissionMetadataFile is the name of the file storing the metadata of a submission.
+const submissionMetadataFile = "metadata.json"
+
+// submissionMetadata holds the information about a submission that is not kept in its file names.
+type submissionMetadata struct {
+	OriginalFileName string `json:"originalFileName"`
+}
+
+// userOutputPattern and userStderrPattern match the output files stored by the worker. They always follow the
+// {number}.out and {number}.err format, independent of the OUTPUT_FILE_PATTERN configured for the task files.
+var (
+	userOutputPattern = regexp.MustCompile(`^(\d+)\.out$`)
+	userStderrPattern = regexp.MustCompile(`^(\d+)\.err$`)
+)
+
 // TaskService handles operations related to task management.
 type TaskService struct {
 	config        *config.Config
@@ -29,7 +55,7 @@ func NewTaskService(cfg *config.Config, tu *taskutils.TaskUtils) *TaskService {
 	return &TaskService{
 		config:        cfg,
 		tu:            tu,
-		taskDirectory: filepath.Join(cfg.RootDirectory, "tasks"),
+		taskDirectory: cfg.TasksDirectory(),
 	}
 }
 
@@ -44,6 +70,11 @@ func (ts *TaskService) CreateTaskDirectory(taskID int, files map[string][]byte,
 	outputDir := filepath.Join(srcDir, "output")
 	descriptionFile := filepath.Join(srcDir, "description.pdf")
 
+	// Validate the files before touching the disk
+	if err := ts.ValidateTaskFiles(files); err != nil {
+		return err
+	}
+
 	var backupDir string
 	shouldRestore := false
 
@@ -86,20 +117,8 @@ func (ts *TaskService) CreateTaskDirectory(taskID int, files map[string][]byte,
 		return ErrFailedCreateDirectory
 	}
 
-	// Validate the number of input and output files
-	if err := ts.tu.ValidateFiles(files); err != nil {
-		// Restore the previous state if validation fails
-		if shouldRestore {
-			restoreError := ts.tu.RestoreDirectory(backupDir, taskDir)
-			if restoreError != nil {
-				return ErrFailedRestoreDirectory
-			}
-		}
-		return &InternalServerError{err.Error()}
-	}
-
 	// Create the description.pdf file
-	if err := os.WriteFile(descriptionFile, files["src/description.pdf"], 0644); err != nil {
+	if err := os.WriteFile(descriptionFile, files["src/description.pdf"], ts.config.FilePerm()); err != nil {
 		// Restore the previous state if writing description fails
 		if shouldRestore {
 			restoreError := ts.tu.RestoreDirectory(backupDir, taskDir)
@@ -133,12 +152,282 @@ func (ts *TaskService) CreateTaskDirectory(taskID int, files map[string][]byte,
 	return nil
 }
 
+// CreateTaskDirectoryFromArchive creates the task directory `task{task_id}` from a task archive
+// (.zip, .tar.gz, .tar.bz2 or .tar.xz) without loading its files into memory.
+// The archive must contain a single main folder with the description.pdf file and the input/ and output/ directories.
+// It is decompressed into a temporary directory, validated like the files of CreateTaskDirectory and moved into place.
+// If the task directory already exists, it is replaced only if overwrite is true, and restored on failure.
+func (ts *TaskService) CreateTaskDirectoryFromArchive(taskID int, archivePath string, overwrite bool) ServiceError {
+	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
+	srcDir := filepath.Join(taskDir, "src")
+
+	// Decompress and validate the archive before touching the task directory
+	tempExtractPath, extractedPath, serviceErr := ts.extractTaskArchive(archivePath)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	defer utils.RemoveDirectory(tempExtractPath)
+
+	// Apply the configured modes so the moved or copied files do not keep the extraction defaults
+	if err := ts.tu.SetPermissions(extractedPath); err != nil {
+		return ErrFailedSaveFiles
+	}
+
+	var backupDir string
+
+	// Check if the task directory already exists
+	if _, err := os.Stat(taskDir); err == nil {
+		if !overwrite {
+			return ErrDirectoryAlreadyExists
+		}
+
+		// Backup the existing directory to a temporary location
+		backupDir, err = ts.tu.BackupDirectory(taskDir)
+		if err != nil {
+			return ErrFailedBackupDirectory
+		}
+
+		// Remove the existing directory to prepare for the new structure
+		if err := os.RemoveAll(taskDir); err != nil {
+			if restoreError := ts.tu.RestoreDirectory(backupDir, taskDir); restoreError != nil {
+				return ErrFailedRestoreDirectory
+			}
+			return ErrFailedRemoveDirectory
+		}
+	}
+
+	// Move the extracted task into place, copying it if the temporary directory is on another device
+	err := os.MkdirAll(taskDir, ts.config.DirPerm())
+	if err == nil {
+		if renameErr := os.Rename(extractedPath, srcDir); renameErr != nil {
+			err = ts.tu.CopyDir(extractedPath, srcDir)
+		}
+	}
+	if err != nil {
+		if backupDir != "" {
+			if restoreError := ts.tu.RestoreDirectory(backupDir, taskDir); restoreError != nil {
+				return ErrFailedRestoreDirectory
+			}
+		} else {
+			utils.RemoveDirectory(taskDir)
+		}
+		return ErrFailedSaveFiles
+	}
+
+	// Remove the backup directory after successful operation
+	if backupDir != "" {
+		if err := os.RemoveAll(backupDir); err != nil {
+			return ErrFailedRemoveDirectory
+		}
+	}
+
+	return nil
+}
+
+// ValidateTaskArchive validates a task archive (.zip, .tar.gz, .tar.bz2 or .tar.xz) with the same rules as
+// CreateTaskDirectoryFromArchive, without creating the task directory. The archive is decompressed into
+// a temporary directory, so its files are never loaded into memory.
+func (ts *TaskService) ValidateTaskArchive(archivePath string) ServiceError {
+	tempExtractPath, _, serviceErr := ts.extractTaskArchive(archivePath)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	utils.RemoveDirectory(tempExtractPath)
+	return nil
+}
+
+// extractTaskArchive decompresses a task archive into a new temporary directory and validates its layout:
+// a single main folder with the description.pdf file and the input/ and output/ directories, whose files are
+// validated like the files of CreateTaskDirectory. It returns the temporary directory, which the caller must remove,
+// and the path of the main folder. On failure the temporary directory is already removed.
+func (ts *TaskService) extractTaskArchive(archivePath string) (string, string, ServiceError) {
+	tempExtractPath, err := os.MkdirTemp("", "task_*")
+	if err != nil {
+		return "", "", ErrFailedCreateDirectory
+	}
+
+	extractedPath, serviceErr := ts.validateExtractedTask(archivePath, tempExtractPath)
+	if serviceErr != nil {
+		utils.RemoveDirectory(tempExtractPath)
+		return "", "", serviceErr
+	}
+	return tempExtractPath, extractedPath, nil
+}
+
+// validateExtractedTask decompresses the task archive into tempExtractPath and validates it,
+// returning the path of its main folder.
+func (ts *TaskService) validateExtractedTask(archivePath, tempExtractPath string) (string, ServiceError) {
+	if err := utils.DecompressArchive(archivePath, tempExtractPath); err != nil {
+		return "", NewErrorWithReason(ErrFailedDecompressArchive, err.Error())
+	}
+
+	entries, err := os.ReadDir(tempExtractPath)
+	if err != nil {
+		return "", ErrFailedReadArchive
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return "", ErrInvalidTaskArchive
+	}
+	extractedPath := filepath.Join(tempExtractPath, entries[0].Name())
+
+	// Validate the layout of the task, only the names of the files are needed
+	files := make(map[string][]byte)
+	hasInputDir, hasOutputDir := false, false
+	err = filepath.WalkDir(extractedPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(extractedPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if d.IsDir() {
+			switch relPath {
+			case ".":
+			case "input":
+				hasInputDir = true
+			case "output":
+				hasOutputDir = true
+			default:
+				return fmt.Errorf("unrecognized directory %s", relPath)
+			}
+			return nil
+		}
+		files["src/"+relPath] = nil
+		return nil
+	})
+	if err != nil {
+		return "", NewErrorWithReason(ErrFailedValidateFiles, err.Error())
+	}
+	if !hasInputDir {
+		return "", NewErrorWithReason(ErrFailedValidateFiles, "input directory is missing in the archive")
+	}
+	if !hasOutputDir {
+		return "", NewErrorWithReason(ErrFailedValidateFiles, "output directory is missing in the archive")
+	}
+	if err := ts.ValidateTaskFiles(files); err != nil {
+		return "", err
+	}
+
+	return extractedPath, nil
+}
+
+// CopyTask creates the task `task{dstTaskID}` from the src directory (description, input and output files)
+// of the task `task{srcTaskID}`. The submissions of the source task are not copied.
+// If the destination directory already exists, it is replaced only if overwrite is true, and restored on failure.
+func (ts *TaskService) CopyTask(srcTaskID, dstTaskID int, overwrite bool) ServiceError {
+	srcTaskSrcDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", srcTaskID), "src")
+	dstTaskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", dstTaskID))
+
+	if srcTaskID == dstTaskID {
+		return ErrCopyTaskOntoItself
+	}
+
+	// Check whether the source task exists
+	if _, err := os.Stat(srcTaskSrcDir); os.IsNotExist(err) {
+		return ErrInvalidTaskID
+	}
+
+	var backupDir string
+
+	// Check if the destination task directory already exists
+	if _, err := os.Stat(dstTaskDir); err == nil {
+		if !overwrite {
+			return ErrDirectoryAlreadyExists
+		}
+
+		// Backup the existing directory to a temporary location
+		backupDir, err = ts.tu.BackupDirectory(dstTaskDir)
+		if err != nil {
+			return ErrFailedBackupDirectory
+		}
+
+		// Remove the existing directory to prepare for the copy
+		if err := os.RemoveAll(dstTaskDir); err != nil {
+			if restoreError := ts.tu.RestoreDirectory(backupDir, dstTaskDir); restoreError != nil {
+				return ErrFailedRestoreDirectory
+			}
+			return ErrFailedRemoveDirectory
+		}
+	}
+
+	// Copy the src directory only, leaving the submissions behind
+	if err := ts.tu.CopyDir(srcTaskSrcDir, filepath.Join(dstTaskDir, "src")); err != nil {
+		if backupDir != "" {
+			if restoreError := ts.tu.RestoreDirectory(backupDir, dstTaskDir); restoreError != nil {
+				return ErrFailedRestoreDirectory
+			}
+		} else {
+			_ = os.RemoveAll(dstTaskDir)
+		}
+		return ErrFailedCopyTask
+	}
+
+	// Remove the backup directory after successful operation
+	if backupDir != "" {
+		if err := os.RemoveAll(backupDir); err != nil {
+			return ErrFailedRemoveDirectory
+		}
+	}
+
+	return nil
+}
+
+// ValidateTaskFiles checks the naming, the counts of input and output files and the presence of the description
+// without touching the disk. It returns ErrFailedValidateFiles with the specific reason if the files are invalid.
+func (ts *TaskService) ValidateTaskFiles(files map[string][]byte) ServiceError {
+	if err := ts.tu.ValidateFiles(files); err != nil {
+		return NewErrorWithReason(ErrFailedValidateFiles, err.Error())
+	}
+	return nil
+}
+
 // CreateUserSubmission creates a new submission directory for a user's task submission.
 // It creates a directory `submissions/user{user_id}/submission{n}/`, where n is an incrementing submission number.
 // It places the user's submission file (e.g., solution.{ext}) inside the submission folder
 // and creates an empty `output/` folder for the generated output files.
+// If PreserveFileNames is enabled in the configuration, the original file name is stored in the submission
+// metadata so that GetUserSubmission returns it instead of solution.{ext}.
 // It returns the submission number and any ServiceError encountered.
 func (ts *TaskService) CreateUserSubmission(taskID int, userID int, userFile []byte, fileName string) (int, ServiceError) {
+	// Get the file extension, the allowed extensions are validated when saving the file
+	fileExtension := strings.ToLower(filepath.Ext(fileName))
+	if fileExtension == "" {
+		return 0, ErrFileHasNoExtension
+	}
+
+	// Save the user's file in the submission directory with the correct extension
+	submissionNumber, err := ts.CreateUserSubmissionFiles(taskID, userID, map[string][]byte{
+		"solution" + fileExtension: userFile,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Keep the original file name, as some languages (e.g. Java) need it to compile the solution
+	if ts.config.PreserveFileNames {
+		submissionDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNumber))
+		metadata, err := json.Marshal(submissionMetadata{OriginalFileName: filepath.Base(fileName)})
+		if err == nil {
+			err = os.WriteFile(filepath.Join(submissionDir, submissionMetadataFile), metadata, ts.config.FilePerm())
+		}
+		if err != nil {
+			// Remove the incomplete submission so it does not take up a submission number
+			utils.RemoveDirectory(submissionDir)
+			return 0, ErrFailedSaveSubmissionMetadata
+		}
+	}
+
+	return submissionNumber, nil
+}
+
+// CreateUserSubmissionFiles creates a new submission directory for a user's task submission made of several files,
+// e.g. multiple source files or a build file. The files are stored under their own names in the submission directory,
+// next to an empty `output/` folder for the generated output files. Every file must have an allowed extension.
+// It returns the submission number and any ServiceError encountered.
+func (ts *TaskService) CreateUserSubmissionFiles(taskID int, userID int, files map[string][]byte) (int, ServiceError) {
 	// Define paths
 	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
 	submissionsDir := filepath.Join(taskDir, "submissions")
@@ -149,9 +438,29 @@ func (ts *TaskService) CreateUserSubmission(taskID int, userID int, userFile []b
 		return 0, ErrInvalidTaskID
 	}
 
+	if len(files) == 0 {
+		return 0, ErrNoSubmissionFiles
+	}
+
+	// Validate the name and extension of every file before touching the disk
+	for fileName := range files {
+		if fileName != filepath.Base(fileName) || fileName == submissionMetadataFile {
+			return 0, ErrInvalidSubmissionFileName
+		}
+
+		fileExtension := strings.ToLower(filepath.Ext(fileName))
+		if fileExtension == "" {
+			return 0, ErrFileHasNoExtension
+		}
+
+		if !ts.tu.IsAllowedFileExtension(fileExtension) {
+			return 0, ErrFileExtensionNotAllowed
+		}
+	}
+
 	// Ensure the submissions directory exists
 	if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
-		err := os.MkdirAll(submissionsDir, os.ModePerm)
+		err := os.MkdirAll(submissionsDir, ts.config.DirPerm())
 		if err != nil {
 			return 0, ErrFailedCreateSubmissionDir
 		}
@@ -159,22 +468,12 @@ func (ts *TaskService) CreateUserSubmission(taskID int, userID int, userFile []b
 
 	// Ensure the user directory exists
 	if _, err := os.Stat(userDir); os.IsNotExist(err) {
-		err := os.MkdirAll(userDir, os.ModePerm)
+		err := os.MkdirAll(userDir, ts.config.DirPerm())
 		if err != nil {
 			return 0, ErrFailedCreateDirectory
 		}
 	}
 
-	// Get the file extension and validate it
-	fileExtension := strings.ToLower(filepath.Ext(fileName))
-	if fileExtension == "" {
-		return 0, ErrFileHasNoExtension
-	}
-
-	if !ts.tu.IsAllowedFileExtension(fileExtension) {
-		return 0, ErrFileExtensionNotAllowed
-	}
-
 	// Get the next submission number by counting existing submission directories
 	submissionNumber, err := ts.tu.GetNextSubmissionNumber(userDir)
 	if err != nil {
@@ -186,15 +485,18 @@ func (ts *TaskService) CreateUserSubmission(taskID int, userID int, userFile []b
 	outputDir := filepath.Join(submissionDir, "output")
 
 	// Create the submission directory and the empty output directory
-	err = os.MkdirAll(outputDir, os.ModePerm)
+	err = os.MkdirAll(outputDir, ts.config.DirPerm())
 	if err != nil {
 		return 0, ErrFailedCreateSubmissionDir
 	}
 
-	// Save the user's file in the submission directory with the correct extension
-	userFilePath := filepath.Join(submissionDir, "solution"+fileExtension)
-	if err := os.WriteFile(userFilePath, userFile, 0644); err != nil {
-		return 0, ErrFailedSaveUserFile
+	// Save the user's files in the submission directory
+	for fileName, fileContent := range files {
+		if err := os.WriteFile(filepath.Join(submissionDir, fileName), fileContent, ts.config.FilePerm()); err != nil {
+			// Remove the incomplete submission so it does not take up a submission number
+			utils.RemoveDirectory(submissionDir)
+			return 0, ErrFailedSaveUserFile
+		}
 	}
 
 	return submissionNumber, nil
@@ -202,7 +504,9 @@ func (ts *TaskService) CreateUserSubmission(taskID int, userID int, userFile []b
 
 // StoreUserOutputs saves output files generated by the user's program inside the appropriate output/ folder
 // under the user's specific submission directory, validating format and matching the task's expected output files.
-func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber int, outputFiles map[string][]byte) ServiceError {
+// If the output folder already contains files, they are replaced only if overwrite is true. The existing outputs
+// are backed up first and restored if storing the new ones fails, so a submission can be re-graded safely.
+func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber int, outputFiles map[string][]byte, overwrite bool) ServiceError {
 	// Define paths for the task, user, and specific submission directories
 	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
 	expectedOutputDir := filepath.Join(taskDir, "src", "output")
@@ -220,6 +524,9 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 		return ErrSubmissionDirDoesNotExist
 	}
 
+	var backupDir string
+	shouldRestore := false
+
 	// Verify if the output directory already has files
 	if _, err := os.Stat(outputDir); err == nil {
 		entries, err := os.ReadDir(outputDir)
@@ -227,22 +534,69 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 			return ErrFailedReadOutputDirectory
 		}
 		if len(entries) > 0 {
-			return ErrOutputDirContainsFiles
+			if !overwrite {
+				return ErrOutputDirContainsFiles
+			}
+
+			// Backup the existing outputs to a temporary location
+			backupDir, err = ts.tu.BackupDirectory(outputDir)
+			if err != nil {
+				return ErrFailedBackupDirectory
+			}
+			shouldRestore = true
+
+			// Clear the existing outputs to prepare for the new ones
+			if err := os.RemoveAll(outputDir); err != nil {
+				if restoreError := ts.tu.RestoreDirectory(backupDir, outputDir); restoreError != nil {
+					return ErrFailedRestoreDirectory
+				}
+				return ErrFailedRemoveDirectory
+			}
+			if err := os.MkdirAll(outputDir, ts.config.DirPerm()); err != nil {
+				if restoreError := ts.tu.RestoreDirectory(backupDir, outputDir); restoreError != nil {
+					return ErrFailedRestoreDirectory
+				}
+				return ErrFailedCreateDirectory
+			}
 		}
 	} else if os.IsNotExist(err) {
 		// Create the output directory if it doesn't exist
-		if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		if err := os.MkdirAll(outputDir, ts.config.DirPerm()); err != nil {
 			return ErrFailedCreateDirectory
 		}
 	} else {
 		return ErrFailedAccessOutputDirectory
 	}
 
+	// Save the outputs, restoring the previous ones on failure
+	if err := ts.saveUserOutputs(outputDir, expectedFiles, outputFiles); err != nil {
+		if shouldRestore {
+			if restoreError := ts.tu.RestoreDirectory(backupDir, outputDir); restoreError != nil {
+				return ErrFailedRestoreDirectory
+			}
+		}
+		return err
+	}
+
+	// Remove the backup directory after successful operation
+	if backupDir != "" {
+		if err := os.RemoveAll(backupDir); err != nil {
+			return ErrFailedRemoveDirectory
+		}
+	}
+
+	return nil
+}
+
+// saveUserOutputs validates the user's output files against the task's expected output files and saves them
+// in the output directory. The user's outputs are always named {number}.out and {number}.err, and are matched
+// by number with the expected output files named after the configured output pattern.
+func (ts *TaskService) saveUserOutputs(outputDir string, expectedFiles []os.DirEntry, outputFiles map[string][]byte) ServiceError {
 	// If there's only one file named "compile-error.err", save it and return
 	if len(outputFiles) == 1 {
 		for fileName := range outputFiles {
 			if fileName == "compile-err.err" {
-				err = ts.tu.SaveCompileErrorFile(outputDir, outputFiles[fileName])
+				err := ts.tu.SaveCompileErrorFile(outputDir, outputFiles[fileName])
 				if err != nil {
 					return ErrFailedToSaveCompileError
 				}
@@ -253,9 +607,8 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 
 	// Count the number of output files provided by the user
 	outputFilesCount := 0
-	re := regexp.MustCompile(`^(\d+)\.out$`)
 	for fileName := range outputFiles {
-		if matches := re.FindStringSubmatch(fileName); matches != nil {
+		if matches := userOutputPattern.FindStringSubmatch(fileName); matches != nil {
 			outputFilesCount++
 		}
 	}
@@ -264,8 +617,9 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 	expectedOutputCount := 0
 	expectedNumbers := make(map[int]bool)
 
+	expectedPattern := ts.tu.OutputPattern()
 	for _, file := range expectedFiles {
-		if matches := re.FindStringSubmatch(file.Name()); matches != nil {
+		if matches := expectedPattern.FindStringSubmatch(file.Name()); matches != nil {
 			num, _ := strconv.Atoi(matches[1])
 			expectedNumbers[num] = true
 			expectedOutputCount++
@@ -284,8 +638,8 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 	// Save output files in the original name with the {number}.out or {number}.err format
 	for fileName, fileContent := range outputFiles {
 		baseName := filepath.Base(fileName)
-		outputMatches := regexp.MustCompile(`^(\d+)\.out$`).FindStringSubmatch(baseName)
-		stderrMatches := regexp.MustCompile(`^(\d+)\.err$`).FindStringSubmatch(baseName)
+		outputMatches := userOutputPattern.FindStringSubmatch(baseName)
+		stderrMatches := userStderrPattern.FindStringSubmatch(baseName)
 
 		if outputMatches != nil {
 			// Handle output files
@@ -306,7 +660,7 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 			}
 
 			// Save the output file in the output directory
-			if err := os.WriteFile(filepath.Join(outputDir, baseName), fileContent, 0644); err != nil {
+			if err := os.WriteFile(filepath.Join(outputDir, baseName), fileContent, ts.config.FilePerm()); err != nil {
 				return ErrFailedSaveOutputFile
 			}
 		} else if stderrMatches != nil {
@@ -322,7 +676,7 @@ func (ts *TaskService) StoreUserOutputs(taskID int, userID int, submissionNumber
 			}
 
 			// Save the stderr file in the output directory
-			if err := os.WriteFile(filepath.Join(outputDir, baseName), fileContent, 0644); err != nil {
+			if err := os.WriteFile(filepath.Join(outputDir, baseName), fileContent, ts.config.FilePerm()); err != nil {
 				return ErrFailedSaveStderrFile
 			}
 		} else {
@@ -346,112 +700,147 @@ func (ts *TaskService) GetTaskFiles(taskID int) (string, ServiceError) {
 		return "", ErrTaskSrcDirDoesNotExist
 	}
 
-	// Create a temporary file for the TAR.GZ archive
-	tarFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("task%dFiles.tar.gz", taskID))
-	tarFile, err := os.Create(tarFilePath)
+	// Create a temporary TAR.GZ archive with the src directory under the root folder
+	rootName := ts.archiveRoot(fmt.Sprintf("task%dFiles", taskID))
+	return ts.createArchive(fmt.Sprintf("task%dFiles", taskID), ArchiveFormatTarGz,
+		[]archiveEntry{{path: srcDir, name: filepath.Join(rootName, "src")}}, nil, nil)
+}
+
+// GetUserSubmission fetches the specific submission file for a user in a given task.
+// It returns the original file name if it was preserved when the submission was created.
+// Submissions made of several files are rejected with ErrMultipleProgramFilesFound.
+func (ts *TaskService) GetUserSubmission(taskID int, userID int, submissionNum int) ([]byte, string, ServiceError) {
+	// Define the path to the specific submission directory
+	submissionDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum))
+
+	// Check if the submission directory exists
+	if _, err := os.Stat(submissionDir); os.IsNotExist(err) {
+		return nil, "", ErrSubmissionDirDoesNotExist
+	}
+
+	// Read files in the submission directory to locate the program file
+	fileNames, err := submissionFileNames(submissionDir)
 	if err != nil {
-		return "", ErrFailedCreateTarFile
+		return nil, "", ErrFailedReadSubmissionDirectory
 	}
-	defer utils.CloseIO(tarFile)
 
-	// Initialize gzip writer
-	gzipWriter := gzip.NewWriter(tarFile)
-	defer utils.CloseIO(gzipWriter)
+	// The submission has to consist of a single program file, multi-file submissions are fetched with GetUserSubmissionFiles
+	if len(fileNames) == 0 {
+		return nil, "", ErrNoProgramFileFound
+	}
+	if len(fileNames) > 1 {
+		return nil, "", ErrMultipleProgramFilesFound
+	}
+	programFile := fileNames[0]
 
-	// Initialize tar writer
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer utils.CloseIO(tarWriter)
+	// Read the content of the program file
+	programFilePath := filepath.Join(submissionDir, programFile)
+	fileContent, err := os.ReadFile(programFilePath)
+	if err != nil {
+		return nil, "", ErrFailedReadProgramFile
+	}
 
-	// Walk through the src directory and add files to the TAR archive
-	err = filepath.Walk(srcDir, func(filePath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return ErrFailedAccessFile
-		}
+	// Use the original file name from the metadata if it was preserved
+	originalFileName, serviceErr := ts.originalFileName(submissionDir)
+	if serviceErr != nil {
+		return nil, "", serviceErr
+	}
+	if originalFileName != "" {
+		programFile = originalFileName
+	}
 
-		// Determine relative path for maintaining directory structure
-		relPath, err := filepath.Rel(filepath.Dir(srcDir), filePath) // root folder for src
-		if err != nil {
-			return ErrFailedDetermineRelPath
-		}
+	return fileContent, programFile, nil
+}
 
-		// Set up the TAR header
-		header, err := tar.FileInfoHeader(info, info.Name())
-		if err != nil {
-			return ErrFailedCreateTarHeader
-		}
-		header.Name = filepath.Join(fmt.Sprintf("task%dFiles", taskID), relPath)
+// originalFileName returns the original name of the submitted file if it was preserved in the metadata
+// of the submission, or an empty string otherwise.
+func (ts *TaskService) originalFileName(submissionDir string) (string, ServiceError) {
+	metadataContent, err := os.ReadFile(filepath.Join(submissionDir, submissionMetadataFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", ErrFailedReadSubmissionMetadata
+	}
 
-		// Write the header
-		if err := tarWriter.WriteHeader(header); err != nil {
-			return ErrFailedWriteTarHeader
-		}
+	var metadata submissionMetadata
+	if err := json.Unmarshal(metadataContent, &metadata); err != nil {
+		return "", ErrFailedReadSubmissionMetadata
+	}
+	return metadata.OriginalFileName, nil
+}
 
-		// If it's a directory, skip writing the content
-		if info.IsDir() {
-			return nil
-		}
+// solutionRenames maps the solution.{ext} file of a submission to its original name if it was preserved,
+// so that archives store the solution file under that name.
+func (ts *TaskService) solutionRenames(submissionDir string) (map[string]string, ServiceError) {
+	originalFileName, serviceErr := ts.originalFileName(submissionDir)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
 
-		// Write the file content
-		file, err := os.Open(filePath)
+	renames := make(map[string]string)
+	if originalFileName != "" {
+		solutionFiles, err := filepath.Glob(filepath.Join(submissionDir, "solution.*"))
 		if err != nil {
-			return ErrFailedOpenFile
+			return nil, ErrFailedSearchSolutionFile
 		}
-		defer utils.CloseIO(file)
-
-		if _, err := io.Copy(tarWriter, file); err != nil {
-			return ErrFailedWriteFileToTar
+		if len(solutionFiles) == 1 {
+			renames[filepath.Base(solutionFiles[0])] = originalFileName
 		}
+	}
+	return renames, nil
+}
 
-		return nil
-	})
+// submissionFileNames returns the names of the submitted files in submissionDir in lexical order,
+// skipping the output/ directory and the submission metadata.
+func submissionFileNames(submissionDir string) ([]string, error) {
+	entries, err := os.ReadDir(submissionDir)
 	if err != nil {
-		return "", ErrFailedAddFilesToTar
+		return nil, err
 	}
 
-	// Return the path to the created TAR.GZ file
-	return tarFilePath, nil
+	var fileNames []string
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == submissionMetadataFile {
+			continue
+		}
+		fileNames = append(fileNames, entry.Name())
+	}
+	return fileNames, nil
 }
 
-// GetUserSubmission fetches the specific submission file for a user in a given task.
-func (ts *TaskService) GetUserSubmission(taskID int, userID int, submissionNum int) ([]byte, string, ServiceError) {
+// GetUserSubmissionFiles fetches all files of a user's submission in a given task, keyed by their file names.
+// Unlike GetUserSubmission it supports submissions made of several files (e.g. a .c file and a header).
+// The output/ directory and the submission metadata are not included.
+func (ts *TaskService) GetUserSubmissionFiles(taskID int, userID int, submissionNum int) (map[string][]byte, ServiceError) {
 	// Define the path to the specific submission directory
 	submissionDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum))
 
 	// Check if the submission directory exists
 	if _, err := os.Stat(submissionDir); os.IsNotExist(err) {
-		return nil, "", ErrSubmissionDirDoesNotExist
+		return nil, ErrSubmissionDirDoesNotExist
 	}
 
-	// Read files in the submission directory to locate the program file
-	files, err := os.ReadDir(submissionDir)
+	fileNames, err := submissionFileNames(submissionDir)
 	if err != nil {
-		return nil, "", ErrFailedReadSubmissionDirectory
+		return nil, ErrFailedReadSubmissionDirectory
 	}
 
-	// Find the single program file in the directory
-	var programFile string
-	for _, file := range files {
-		if !file.IsDir() && strings.HasPrefix(file.Name(), "solution") {
-			if programFile != "" {
-				return nil, "", ErrMultipleProgramFilesFound
-			}
-			programFile = file.Name()
+	// Read every file of the submission
+	files := make(map[string][]byte)
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(filepath.Join(submissionDir, fileName))
+		if err != nil {
+			return nil, ErrFailedReadProgramFile
 		}
+		files[fileName] = content
 	}
 
-	// Check if a program file was found
-	if programFile == "" {
-		return nil, "", ErrNoProgramFileFound
-	}
-
-	// Read the content of the program file
-	programFilePath := filepath.Join(submissionDir, programFile)
-	fileContent, err := os.ReadFile(programFilePath)
-	if err != nil {
-		return nil, "", ErrFailedReadProgramFile
+	if len(files) == 0 {
+		return nil, ErrNoProgramFileFound
 	}
 
-	return fileContent, programFile, nil
+	return files, nil
 }
 
 // GetInputOutput retrieves the specific input and output files for a given task and returns them in a .tar.gz archive.
@@ -471,66 +860,78 @@ func (ts *TaskService) GetInputOutput(taskID int, inputOutputID int) (string, Se
 	}
 
 	// Locate specific input and output files based on inputOutputID
-	inputFilePath := filepath.Join(inputDir, fmt.Sprintf("%d.in", inputOutputID))
-	outputFilePath := filepath.Join(outputDir, fmt.Sprintf("%d.out", inputOutputID))
-
-	// Ensure the input and output files exist
-	if _, err := os.Stat(inputFilePath); os.IsNotExist(err) {
-		return "", ErrInputFileDoesNotExist
+	inputFiles, err := ts.tu.NumberedFiles(inputDir, ts.tu.InputPattern())
+	if err != nil {
+		return "", ErrFailedReadInputFiles
 	}
-	if _, err := os.Stat(outputFilePath); os.IsNotExist(err) {
-		return "", ErrOutputFileDoesNotExist
+	outputFiles, err := ts.tu.NumberedFiles(outputDir, ts.tu.OutputPattern())
+	if err != nil {
+		return "", ErrFailedReadOutputFiles
 	}
 
-	// Create a temporary .tar.gz file
-	tarFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("task%d_inputOutput%d.tar.gz", taskID, inputOutputID))
-	tarFile, err := os.Create(tarFilePath)
-	if err != nil {
-		return "", ErrFailedCreateTarFile
+	// Ensure the input and output files exist, reporting how many pairs the task has otherwise
+	inputFilePath, ok := inputFiles[inputOutputID]
+	if !ok {
+		return "", ts.withPairCount(ErrInputFileDoesNotExist, taskID)
+	}
+	outputFilePath, ok := outputFiles[inputOutputID]
+	if !ok {
+		return "", ts.withPairCount(ErrOutputFileDoesNotExist, taskID)
 	}
-	defer utils.CloseIO(tarFile)
 
-	// Initialize gzip writer
-	gzipWriter := gzip.NewWriter(tarFile)
-	defer utils.CloseIO(gzipWriter)
+	// Create a temporary .tar.gz file with only the base file names, unless a root folder is configured
+	rootName := ts.archiveRoot("")
+	return ts.createArchive(fmt.Sprintf("task%d_inputOutput%d", taskID, inputOutputID), ArchiveFormatTarGz, []archiveEntry{
+		{path: inputFilePath, name: filepath.Join(rootName, filepath.Base(inputFilePath))},
+		{path: outputFilePath, name: filepath.Join(rootName, filepath.Base(outputFilePath))},
+	}, nil, nil)
+}
 
-	// Initialize tar writer
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer utils.CloseIO(tarWriter)
+// CountInputOutputPairs returns the number of valid input/output pairs of a task,
+// i.e. the numbers n for which both `{n}.in` and `{n}.out` exist.
+func (ts *TaskService) CountInputOutputPairs(taskID int) (int, ServiceError) {
+	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
+	inputDir := filepath.Join(taskDir, "src", "input")
+	outputDir := filepath.Join(taskDir, "src", "output")
 
-	// Add input and output files to the TAR archive with only the base filename
-	for _, filePath := range []string{inputFilePath, outputFilePath} {
-		// Open the file to read content
-		file, err := os.Open(filePath)
-		if err != nil {
-			return "", ErrFailedOpenFile
-		}
+	// Check whether task directory exists
+	if _, err := os.Stat(taskDir); os.IsNotExist(err) {
+		return 0, ErrInvalidTaskID
+	}
 
-		// Gather file info and set up the TAR header
-		info, err := file.Stat()
-		if err != nil {
-			return "", ErrFailedGetFileInfo
-		}
-		header, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return "", ErrFailedCreateTarHeader
-		}
-		// Use only the base filename for header.Name to avoid folder structure
-		header.Name = info.Name()
+	inputFiles, err := ts.tu.NumberedFiles(inputDir, ts.tu.InputPattern())
+	if os.IsNotExist(err) {
+		return 0, ErrInputDirectoryDoesNotExist
+	} else if err != nil {
+		return 0, ErrFailedReadInputFiles
+	}
 
-		// Write the header and file content to the TAR archive
-		if err := tarWriter.WriteHeader(header); err != nil {
-			return "", ErrFailedWriteTarHeader
-		}
-		if _, err := io.Copy(tarWriter, file); err != nil {
-			return "", ErrFailedWriteFileToTar
-		}
+	outputFiles, err := ts.tu.NumberedFiles(outputDir, ts.tu.OutputPattern())
+	if os.IsNotExist(err) {
+		return 0, ErrOutputDirectoryDoesNotExist
+	} else if err != nil {
+		return 0, ErrFailedReadOutputFiles
+	}
 
-		utils.CloseIO(file)
+	// Count the output files that have a matching input file
+	pairs := 0
+	for num := range outputFiles {
+		if _, ok := inputFiles[num]; ok {
+			pairs++
+		}
 	}
 
-	// Return the path to the created TAR.GZ file
-	return tarFilePath, nil
+	return pairs, nil
+}
+
+// withPairCount adds the number of input/output pairs of the task to the given error.
+// The error is returned unchanged if the pairs cannot be counted.
+func (ts *TaskService) withPairCount(err ServiceError, taskID int) ServiceError {
+	pairs, countErr := ts.CountInputOutputPairs(taskID)
+	if countErr != nil {
+		return err
+	}
+	return NewErrorWithReason(err, fmt.Sprintf("task %d has %d input/output pairs", taskID, pairs))
 }
 
 // DeleteTask deletes the directory of a specific task, including all associated files and submissions.
@@ -556,13 +957,15 @@ func (ts *TaskService) DeleteTask(taskID int) ServiceError {
 }
 
 // GetUserSolutionPackage fetches the specific package for a given task, user, and submission number,
-// organizing it in a structured .tar.gz archive containing inputs, outputs, and the solution file.
+// organizing it in a structured .tar.gz archive containing inputs, outputs, and the submitted files.
+// A solution.{ext} file keeps its original name if it was preserved, and the files of a submission
+// created with CreateUserSubmissionFiles are stored under their own names.
 func (ts *TaskService) GetUserSolutionPackage(taskID, userID, submissionNum int) (string, ServiceError) {
 	// Define paths for the task directories and files
 	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
 	inputDir := filepath.Join(taskDir, "src", "input")
 	outputDir := filepath.Join(taskDir, "src", "output")
-	solutionPattern := filepath.Join(taskDir, "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum), "solution.*")
+	submissionDir := filepath.Join(taskDir, "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum))
 
 	// Check if the input and output directories exist
 	if _, err := os.Stat(inputDir); os.IsNotExist(err) {
@@ -572,116 +975,389 @@ func (ts *TaskService) GetUserSolutionPackage(taskID, userID, submissionNum int)
 		return "", ErrOutputDirectoryDoesNotExist
 	}
 
-	// Find the solution file with any extension
-	solutionFiles, err := filepath.Glob(solutionPattern)
-	if err != nil {
+	// Find the submitted files, a single solution.{ext} file or the files of a multi-file submission
+	solutionFiles, err := submissionFileNames(submissionDir)
+	if err != nil && !os.IsNotExist(err) {
 		return "", ErrFailedSearchSolutionFile
 	}
 	if len(solutionFiles) == 0 {
 		return "", ErrSolutionFileDoesNotExist
 	}
-	if len(solutionFiles) > 1 {
-		return "", ErrMultipleSolutionFilesFound
+
+	// Use the original name of the solution file if it was preserved
+	renames, serviceErr := ts.solutionRenames(submissionDir)
+	if serviceErr != nil {
+		return "", serviceErr
+	}
+
+	rootName := ts.archiveRoot("Task")
+	var entries []archiveEntry
+
+	// Add input files to the "inputs/" folder in the tar
+	inputFiles, err := ts.tu.NumberedFiles(inputDir, ts.tu.InputPattern())
+	if err != nil {
+		return "", ErrFailedReadInputFiles
+	}
+	for _, filePath := range inputFiles {
+		entries = append(entries, archiveEntry{path: filePath, name: filepath.Join(rootName, "inputs", filepath.Base(filePath))})
+	}
+
+	// Add output files to the "outputs/" folder in the tar
+	outputFiles, err := ts.tu.NumberedFiles(outputDir, ts.tu.OutputPattern())
+	if err != nil {
+		return "", ErrFailedReadOutputFiles
+	}
+	for _, filePath := range outputFiles {
+		entries = append(entries, archiveEntry{path: filePath, name: filepath.Join(rootName, "outputs", filepath.Base(filePath))})
+	}
+
+	// Add the submitted files to the tar, preserving their original extensions
+	for _, fileName := range solutionFiles {
+		tarName := fileName
+		if newName, ok := renames[fileName]; ok {
+			tarName = newName
+		}
+		entries = append(entries, archiveEntry{path: filepath.Join(submissionDir, fileName), name: filepath.Join(rootName, tarName)})
 	}
-	solutionFile := solutionFiles[0]
 
 	// Create a temporary .tar.gz file to store the package
-	tarFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("task%d_user%d_submission%d_package.tar.gz", taskID, userID, submissionNum))
-	tarFile, err := os.Create(tarFilePath)
+	return ts.createArchive(fmt.Sprintf("task%d_user%d_submission%d_package", taskID, userID, submissionNum), ArchiveFormatTarGz, entries, nil, nil)
+}
+
+// GetTaskDescription fetches the description file for a given task.
+func (ts *TaskService) GetTaskDescription(taskID int) ([]byte, string, ServiceError) {
+	// Define the path to the task description file
+	descriptionFilePath := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "src", "description.pdf")
+
+	// Check if the description file exists
+	if _, err := os.Stat(descriptionFilePath); os.IsNotExist(err) {
+		return nil, "", ErrDescriptionFileDoesNotExist
+	}
+
+	// Read the content of the description file
+	fileContent, err := os.ReadFile(descriptionFilePath)
 	if err != nil {
-		return "", ErrFailedCreateTarFile
+		return nil, "", ErrFailedReadDescriptionFile
 	}
-	defer utils.CloseIO(tarFile)
 
-	// Initialize gzip and tar writers
-	gzipWriter := gzip.NewWriter(tarFile)
-	defer utils.CloseIO(gzipWriter)
+	return fileContent, "description.pdf", nil
+}
 
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer utils.CloseIO(tarWriter)
+// GetTaskDescriptionStream opens the description file for a given task without reading it into memory,
+// so large descriptions can be streamed to the client. The caller must close the returned reader.
+func (ts *TaskService) GetTaskDescriptionStream(taskID int) (io.ReadCloser, string, ServiceError) {
+	descriptionFilePath := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "src", "description.pdf")
 
-	// Function to add files to the archive with specified path
-	addFileToTar := func(filePath, tarPath string) error {
-		file, err := os.Open(filePath)
-		if err != nil {
-			return ErrFailedOpenFile
-		}
-		defer utils.CloseIO(file)
+	file, err := os.Open(descriptionFilePath)
+	if os.IsNotExist(err) {
+		return nil, "", ErrDescriptionFileDoesNotExist
+	}
+	if err != nil {
+		return nil, "", ErrFailedReadDescriptionFile
+	}
+
+	return file, "description.pdf", nil
+}
+
+// ListAllUserSubmissions returns the submissions of a user across all tasks, sorted by task ID and submission number.
+// Only the `submissions/user{user_id}` directory of each task is scanned. The timestamp of a submission
+// is the modification time of its directory.
+func (ts *TaskService) ListAllUserSubmissions(userID int) ([]UserSubmission, ServiceError) {
+	submissions := make([]UserSubmission, 0)
+
+	// Read all task directories
+	taskEntries, err := os.ReadDir(ts.taskDirectory)
+	if os.IsNotExist(err) {
+		return submissions, nil
+	} else if err != nil {
+		return nil, ErrFailedReadTasksDirectory
+	}
+
+	taskPattern := regexp.MustCompile(`^task(\d+)$`)
+	submissionPattern := regexp.MustCompile(`^submission(\d+)$`)
 
-		info, err := file.Stat()
+	for _, taskEntry := range taskEntries {
+		taskMatches := taskPattern.FindStringSubmatch(taskEntry.Name())
+		if !taskEntry.IsDir() || taskMatches == nil {
+			continue
+		}
+		taskID, err := strconv.Atoi(taskMatches[1])
 		if err != nil {
-			return ErrFailedGetFileInfo
+			continue
 		}
 
-		header, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return ErrFailedCreateTarHeader
+		// Descend only into the submissions directory of the given user
+		userDir := filepath.Join(ts.taskDirectory, taskEntry.Name(), "submissions", fmt.Sprintf("user%d", userID))
+		submissionEntries, err := os.ReadDir(userDir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, ErrFailedReadSubmissionDirectory
 		}
 
-		header.Name = tarPath // Use provided tarPath for directory structure in archive
+		for _, submissionEntry := range submissionEntries {
+			submissionMatches := submissionPattern.FindStringSubmatch(submissionEntry.Name())
+			if !submissionEntry.IsDir() || submissionMatches == nil {
+				continue
+			}
+			submissionNumber, err := strconv.Atoi(submissionMatches[1])
+			if err != nil {
+				continue
+			}
+
+			info, err := submissionEntry.Info()
+			if err != nil {
+				return nil, ErrFailedGetFileInfo
+			}
 
-		if err := tarWriter.WriteHeader(header); err != nil {
-			return ErrFailedWriteTarHeader
+			submissions = append(submissions, UserSubmission{
+				TaskID:           taskID,
+				SubmissionNumber: submissionNumber,
+				Timestamp:        info.ModTime(),
+			})
 		}
+	}
 
-		if _, err := io.Copy(tarWriter, file); err != nil {
-			return ErrFailedWriteFileToTar
+	// Sort the submissions by task ID and submission number
+	sort.Slice(submissions, func(i, j int) bool {
+		if submissions[i].TaskID != submissions[j].TaskID {
+			return submissions[i].TaskID < submissions[j].TaskID
 		}
+		return submissions[i].SubmissionNumber < submissions[j].SubmissionNumber
+	})
 
-		return nil
+	return submissions, nil
+}
+
+// GetSubmissionCount returns the total number of submissions of all users for a given task,
+// counting the `submissions/user{user_id}/submission{n}` directories.
+// A task without any submissions has a count of 0.
+func (ts *TaskService) GetSubmissionCount(taskID int) (int, ServiceError) {
+	taskDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID))
+	submissionsDir := filepath.Join(taskDir, "submissions")
+
+	// Check whether task directory exists
+	if _, err := os.Stat(taskDir); os.IsNotExist(err) {
+		return 0, ErrInvalidTaskID
 	}
 
-	// Add input files to the "inputs/" folder in the tar
-	inputFiles, err := filepath.Glob(filepath.Join(inputDir, "*.in"))
-	if err != nil {
-		return "", ErrFailedReadInputFiles
+	userEntries, err := os.ReadDir(submissionsDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, ErrFailedReadSubmissionDirectory
 	}
-	for _, filePath := range inputFiles {
-		fileName := filepath.Base(filePath)
-		err := addFileToTar(filePath, filepath.Join("Task", "inputs", fileName))
+
+	userPattern := regexp.MustCompile(`^user\d+$`)
+	submissionPattern := regexp.MustCompile(`^submission\d+$`)
+
+	count := 0
+	for _, userEntry := range userEntries {
+		if !userEntry.IsDir() || !userPattern.MatchString(userEntry.Name()) {
+			continue
+		}
+
+		submissionEntries, err := os.ReadDir(filepath.Join(submissionsDir, userEntry.Name()))
 		if err != nil {
-			return "", ErrFailedAddFilesToTar
+			return 0, ErrFailedReadSubmissionDirectory
+		}
+
+		for _, submissionEntry := range submissionEntries {
+			if submissionEntry.IsDir() && submissionPattern.MatchString(submissionEntry.Name()) {
+				count++
+			}
 		}
 	}
 
-	// Add output files to the "outputs/" folder in the tar
-	outputFiles, err := filepath.Glob(filepath.Join(outputDir, "*.out"))
-	if err != nil {
-		return "", ErrFailedReadOutputFiles
+	return count, nil
+}
+
+// ArchiveFormat is the format of the archives created by the TaskService.
+type ArchiveFormat string
+
+const (
+	// ArchiveFormatTarGz creates gzip compressed tar archives.
+	ArchiveFormatTarGz ArchiveFormat = ".tar.gz"
+	// ArchiveFormatZip creates zip archives.
+	ArchiveFormatZip ArchiveFormat = ".zip"
+)
+
+// ArchiveSubmission bundles a complete submission of a user, the submitted source file(s) and the output/ directory
+// with the outputs or the compile-error.err file, into an archive of the given format under a `Submission/` folder,
+// or the configured archive root folder. The solution file keeps its original name if it was preserved.
+// Unlike GetUserSolutionPackage, the task inputs and expected outputs are not included.
+// It returns the path to the created archive.
+func (ts *TaskService) ArchiveSubmission(taskID, userID, submissionNum int, format ArchiveFormat) (string, ServiceError) {
+	submissionDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum))
+
+	if format != ArchiveFormatTarGz && format != ArchiveFormatZip {
+		return "", ErrUnsupportedArchiveFormat
 	}
-	for _, filePath := range outputFiles {
-		fileName := filepath.Base(filePath)
-		err := addFileToTar(filePath, filepath.Join("Task", "outputs", fileName))
-		if err != nil {
-			return "", ErrFailedAddFilesToTar
-		}
+
+	// Check if the submission directory exists
+	if _, err := os.Stat(submissionDir); os.IsNotExist(err) {
+		return "", ErrSubmissionDirDoesNotExist
 	}
 
-	// Add the solution file to the tar, preserving its original extension
-	err = addFileToTar(solutionFile, filepath.Join("Task", filepath.Base(solutionFile)))
+	// Store the solution file under its original name if it was preserved
+	renames, serviceErr := ts.solutionRenames(submissionDir)
+	if serviceErr != nil {
+		return "", serviceErr
+	}
+
+	return ts.createArchive(fmt.Sprintf("task%d_user%d_submission%d_archive", taskID, userID, submissionNum), format,
+		[]archiveEntry{{path: submissionDir, name: ts.archiveRoot("Submission")}}, func(relPath string) bool {
+			return relPath == submissionMetadataFile
+		}, renames)
+}
+
+// archiveRoot returns the root folder of the served archives, the configured ArchiveRoot if set
+// or defaultRoot otherwise, so that all archives can share a single layout.
+func (ts *TaskService) archiveRoot(defaultRoot string) string {
+	if ts.config.ArchiveRoot != "" {
+		return ts.config.ArchiveRoot
+	}
+	return defaultRoot
+}
+
+// archiveEntry is a file or directory written to an archive under name. Directories are added with their contents.
+type archiveEntry struct {
+	path string
+	name string
+}
+
+// createArchive writes the given entries into a new temporary archive of the given format, named after pattern,
+// and returns its path. Files inside directory entries for which skip returns true are left out, and files listed
+// in renames are stored under the given name instead of their own; both are keyed by the path relative to the entry.
+// All archives served by the TaskService are written here, so they share the compression settings.
+func (ts *TaskService) createArchive(pattern string, format ArchiveFormat, entries []archiveEntry, skip func(relPath string) bool, renames map[string]string) (string, ServiceError) {
+	archiveFile, err := os.CreateTemp("", pattern+"_*"+string(format))
 	if err != nil {
-		return "", ErrFailedAddFilesToTar
+		return "", ErrFailedCreateTarFile
+	}
+	archivePath := archiveFile.Name()
+
+	if serviceErr := ts.writeArchive(archiveFile, format, entries, skip, renames); serviceErr != nil {
+		utils.CloseIO(archiveFile)
+		utils.RemoveDirectory(archivePath)
+		return "", serviceErr
+	}
+	if err := archiveFile.Close(); err != nil {
+		utils.RemoveDirectory(archivePath)
+		return "", ErrFailedCreateTarFile
 	}
 
-	// Return the path to the created .tar.gz file
-	return tarFilePath, nil
+	return archivePath, nil
 }
 
-// GetTaskDescription fetches the description file for a given task.
-func (ts *TaskService) GetTaskDescription(taskID int) ([]byte, string, ServiceError) {
-	// Define the path to the task description file
-	descriptionFilePath := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "src", "description.pdf")
+// writeArchive writes the entries of createArchive to archiveFile, closing the archive writers when done.
+func (ts *TaskService) writeArchive(archiveFile io.Writer, format ArchiveFormat, entries []archiveEntry, skip func(relPath string) bool, renames map[string]string) ServiceError {
+	// addEntry writes a single directory or file to the archive
+	var addEntry func(name string, info os.FileInfo, path string) ServiceError
 
-	// Check if the description file exists
-	if _, err := os.Stat(descriptionFilePath); os.IsNotExist(err) {
-		return nil, "", ErrDescriptionFileDoesNotExist
+	switch format {
+	case ArchiveFormatZip:
+		zipWriter := zip.NewWriter(archiveFile)
+		defer utils.CloseIO(zipWriter)
+
+		addEntry = func(name string, info os.FileInfo, path string) ServiceError {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return ErrFailedCreateTarHeader
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+			} else {
+				header.Method = zip.Deflate
+			}
+
+			writer, err := zipWriter.CreateHeader(header)
+			if err != nil {
+				return ErrFailedWriteTarHeader
+			}
+			if info.IsDir() {
+				return nil
+			}
+			return copyFileTo(writer, path)
+		}
+	default:
+		gzipWriter, err := gzip.NewWriterLevel(archiveFile, ts.config.GzipLevel())
+		if err != nil {
+			return ErrFailedCreateTarFile
+		}
+		defer utils.CloseIO(gzipWriter)
+
+		tarWriter := tar.NewWriter(gzipWriter)
+		defer utils.CloseIO(tarWriter)
+
+		addEntry = func(name string, info os.FileInfo, path string) ServiceError {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return ErrFailedCreateTarHeader
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+			}
+
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return ErrFailedWriteTarHeader
+			}
+			if info.IsDir() {
+				return nil
+			}
+			return copyFileTo(tarWriter, path)
+		}
 	}
 
-	// Read the content of the description file
-	fileContent, err := os.ReadFile(descriptionFilePath)
+	for _, entry := range entries {
+		var serviceErr ServiceError
+		err := filepath.Walk(entry.path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				serviceErr = ErrFailedAccessFile
+				return err
+			}
+
+			relPath, err := filepath.Rel(entry.path, path)
+			if err != nil {
+				serviceErr = ErrFailedDetermineRelPath
+				return err
+			}
+			if relPath == "." {
+				relPath = ""
+			} else if skip != nil && skip(filepath.ToSlash(relPath)) {
+				return nil
+			} else if newName, ok := renames[filepath.ToSlash(relPath)]; ok {
+				relPath = newName
+			}
+
+			if serviceErr = addEntry(filepath.ToSlash(filepath.Join(entry.name, relPath)), info, path); serviceErr != nil {
+				return serviceErr
+			}
+			return nil
+		})
+		if serviceErr != nil {
+			return serviceErr
+		}
+		if err != nil {
+			return ErrFailedAddFilesToTar
+		}
+	}
+
+	return nil
+}
+
+// copyFileTo copies the content of the file at path to the writer of an archive entry.
+func copyFileTo(w io.Writer, path string) ServiceError {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, "", ErrFailedReadDescriptionFile
+		return ErrFailedOpenFile
 	}
+	defer utils.CloseIO(file)
 
-	return fileContent, "description.pdf", nil
+	if _, err := io.Copy(w, file); err != nil {
+		return ErrFailedWriteFileToTar
+	}
+	return nil
 }
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+
+	"github.com/mini-maxit/file-storage/internal/api/http/middleware"
 )
 
 // ServiceError is an interface representing a common error type for service errors.
@@ -38,17 +41,60 @@ func (e *InternalServerError) StatusCode() int {
 	return http.StatusInternalServerError
 }
 
+// ErrorWithReason attaches a specific reason to a predefined ServiceError.
+// It keeps the status code of the wrapped error and can be matched against it with errors.Is.
+type ErrorWithReason struct {
+	ServiceError
+	Reason string
+}
+
+func (e *ErrorWithReason) Error() string {
+	return fmt.Sprintf("%s: %s", e.ServiceError.Error(), e.Reason)
+}
+
+func (e *ErrorWithReason) Unwrap() error {
+	return e.ServiceError
+}
+
+// NotFoundError indicates that a requested resource (task, submission or file) does not exist.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) StatusCode() int {
+	return http.StatusNotFound
+}
+
 func NewBadRequestError(message string) *BadRequestError {
 	return &BadRequestError{Message: message}
 }
 
+func NewNotFoundError(message string) *NotFoundError {
+	return &NotFoundError{Message: message}
+}
+
 func NewInternalServerError(message string) *InternalServerError {
 	return &InternalServerError{Message: message}
 }
 
+func NewErrorWithReason(err ServiceError, reason string) *ErrorWithReason {
+	return &ErrorWithReason{ServiceError: err, Reason: reason}
+}
+
 // WriteServiceError handles service errors and writes an HTTP error response in JSON format,
-// including additional context if provided.
-func WriteServiceError(err ServiceError, w http.ResponseWriter, message string, context map[string]interface{}) {
+// including additional context if provided. The error is logged with the request ID from ctx.
+func WriteServiceError(ctx context.Context, err ServiceError, w http.ResponseWriter, message string, errorContext map[string]interface{}) {
+	logger := middleware.Logger(ctx).WithField("status", err.StatusCode())
+	if err.StatusCode() >= http.StatusInternalServerError {
+		logger.Errorf("%s: %v", message, err)
+	} else {
+		logger.Warnf("%s: %v", message, err)
+	}
+
 	// Build the response payload
 	response := map[string]interface{}{
 		"reason":  message,
@@ -56,8 +102,8 @@ func WriteServiceError(err ServiceError, w http.ResponseWriter, message string,
 	}
 
 	// Include context if provided
-	if len(context) > 0 {
-		response["context"] = context
+	if len(errorContext) > 0 {
+		response["context"] = errorContext
 	}
 
 	// Set the content type to application/json
@@ -68,37 +114,48 @@ func WriteServiceError(err ServiceError, w http.ResponseWriter, message string,
 	jsonResponse, _ := json.Marshal(response)
 	_, writeError := w.Write(jsonResponse)
 	if writeError != nil {
-		log.Println(writeError)
+		logger.Errorf("failed to write error response: %v", writeError)
 		return
 	}
 }
 
 // BadRequestErrors
 var (
-	ErrDirectoryAlreadyExists      = NewBadRequestError("the task directory already exists, overwrite not allowed")
-	ErrInvalidTaskID               = NewBadRequestError("invalid taskID: task directory does not exist")
-	ErrFileHasNoExtension          = NewBadRequestError("file has no extension")
-	ErrFileExtensionNotAllowed     = NewBadRequestError("file extension is not allowed")
-	ErrOutputDirContainsFiles      = NewBadRequestError("output directory already contains files")
-	ErrNoProgramFileFound          = NewBadRequestError("no program file found in submission")
-	ErrMultipleProgramFilesFound   = NewBadRequestError("multiple program files found in submission")
-	ErrInputFileDoesNotExist       = NewBadRequestError("input file does not exist")
-	ErrOutputFileDoesNotExist      = NewBadRequestError("output file does not exist")
-	ErrOutputFileMismatch          = NewBadRequestError("number of output files does not match the expected number")
-	ErrUnexpectedOutputFileNumber  = NewBadRequestError("unexpected output file number provided")
-	ErrInvalidStderrFileNumber     = NewBadRequestError("invalid stderr file number provided")
-	ErrDuplicateOutputFileNumber   = NewBadRequestError("duplicate output file number found in user submission")
-	ErrDuplicateStderrFileNumber   = NewBadRequestError("duplicate stderr file number found in user submission")
-	ErrInvalidOutputFileFormat     = NewBadRequestError("output file does not match the required format {number}.out or {number}.err")
-	ErrSubmissionDirDoesNotExist   = NewBadRequestError("submission directory does not exist")
-	ErrFailedReadOutputDirectory   = NewBadRequestError("failed reading output directory")
-	ErrInvalidOutputFileNumber     = NewBadRequestError("invalid output file number provided")
-	ErrTaskSrcDirDoesNotExist      = NewBadRequestError("task src directory does not exist")
-	ErrInputDirectoryDoesNotExist  = NewBadRequestError("input src directory does not exist")
-	ErrOutputDirectoryDoesNotExist = NewBadRequestError("output src directory does not exist")
-	ErrFailedSearchSolutionFile    = NewBadRequestError("failed searching solution file")
-	ErrSolutionFileDoesNotExist    = NewBadRequestError("solution file does not exist")
-	ErrDescriptionFileDoesNotExist = NewBadRequestError("description file does not exist")
+	ErrDirectoryAlreadyExists     = NewBadRequestError("the task directory already exists, overwrite not allowed")
+	ErrFileHasNoExtension         = NewBadRequestError("file has no extension")
+	ErrFileExtensionNotAllowed    = NewBadRequestError("file extension is not allowed")
+	ErrOutputDirContainsFiles     = NewBadRequestError("output directory already contains files")
+	ErrMultipleProgramFilesFound  = NewBadRequestError("multiple program files found in submission")
+	ErrOutputFileMismatch         = NewBadRequestError("number of output files does not match the expected number")
+	ErrUnexpectedOutputFileNumber = NewBadRequestError("unexpected output file number provided")
+	ErrInvalidStderrFileNumber    = NewBadRequestError("invalid stderr file number provided")
+	ErrDuplicateOutputFileNumber  = NewBadRequestError("duplicate output file number found in user submission")
+	ErrDuplicateStderrFileNumber  = NewBadRequestError("duplicate stderr file number found in user submission")
+	ErrInvalidOutputFileFormat    = NewBadRequestError("output file does not match the required format {number}.out or {number}.err")
+	ErrFailedReadOutputDirectory  = NewBadRequestError("failed reading output directory")
+	ErrInvalidOutputFileNumber    = NewBadRequestError("invalid output file number provided")
+	ErrFailedSearchSolutionFile   = NewBadRequestError("failed searching solution file")
+	ErrFailedValidateFiles        = NewBadRequestError("invalid task files")
+	ErrNoSubmissionFiles          = NewBadRequestError("no submission files provided")
+	ErrInvalidSubmissionFileName  = NewBadRequestError("invalid submission file name")
+	ErrCopyTaskOntoItself         = NewBadRequestError("source and destination task are the same")
+	ErrFailedDecompressArchive    = NewBadRequestError("failed to decompress archive")
+	ErrInvalidTaskArchive         = NewBadRequestError("task archive has to contain exactly 1 main folder")
+	ErrUnsupportedArchiveFormat   = NewBadRequestError("unsupported archive format")
+)
+
+// NotFoundErrors
+var (
+	ErrInvalidTaskID               = NewNotFoundError("invalid taskID: task directory does not exist")
+	ErrNoProgramFileFound          = NewNotFoundError("no program file found in submission")
+	ErrInputFileDoesNotExist       = NewNotFoundError("input file does not exist")
+	ErrOutputFileDoesNotExist      = NewNotFoundError("output file does not exist")
+	ErrSubmissionDirDoesNotExist   = NewNotFoundError("submission directory does not exist")
+	ErrTaskSrcDirDoesNotExist      = NewNotFoundError("task src directory does not exist")
+	ErrInputDirectoryDoesNotExist  = NewNotFoundError("input src directory does not exist")
+	ErrOutputDirectoryDoesNotExist = NewNotFoundError("output src directory does not exist")
+	ErrSolutionFileDoesNotExist    = NewNotFoundError("solution file does not exist")
+	ErrDescriptionFileDoesNotExist = NewNotFoundError("description file does not exist")
 )
 
 // InternalServerErrors
@@ -107,7 +164,6 @@ var (
 	ErrFailedRemoveDirectory         = NewInternalServerError("failed to remove existing directory")
 	ErrFailedRestoreDirectory        = NewInternalServerError("failed to restore existing directory")
 	ErrFailedCreateDirectory         = NewInternalServerError("failed to create directory structure")
-	ErrFailedValidateFiles           = NewInternalServerError("failed to validate files")
 	ErrFailedCreateDescription       = NewInternalServerError("failed to create description.pdf")
 	ErrFailedSaveUserFile            = NewInternalServerError("failed to save user file")
 	ErrFailedCreateSubmissionDir     = NewInternalServerError("failed to create submission or output directory")
@@ -130,9 +186,13 @@ var (
 	ErrFailedReadProgramFile         = NewInternalServerError("failed to read program file")
 	ErrFailedGetFileInfo             = NewInternalServerError("failed to get file info")
 	ErrFailedAccessTaskDirectory     = NewInternalServerError("failed to access task directory")
-	ErrMultipleSolutionFilesFound    = NewInternalServerError("multiple solution files found in submission")
 	ErrFailedReadInputFiles          = NewInternalServerError("failed to read input/output files")
 	ErrFailedReadOutputFiles         = NewInternalServerError("failed to read output file")
 	ErrFailedToSaveCompileError      = NewInternalServerError("failed to save compile error")
 	ErrFailedReadDescriptionFile     = NewInternalServerError("failed to read description.pdf")
+	ErrFailedReadTasksDirectory      = NewInternalServerError("failed to read tasks directory")
+	ErrFailedSaveSubmissionMetadata  = NewInternalServerError("failed to save submission metadata")
+	ErrFailedReadSubmissionMetadata  = NewInternalServerError("failed to read submission metadata")
+	ErrFailedCopyTask                = NewInternalServerError("failed to copy task directory")
+	ErrFailedReadArchive             = NewInternalServerError("failed to read decompressed archive")
 )
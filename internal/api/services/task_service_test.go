@@ -2,13 +2,17 @@ package services
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"github.com/mini-maxit/file-storage/internal/api/taskutils"
 	"github.com/mini-maxit/file-storage/utils"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mini-maxit/file-storage/internal/config"
@@ -125,6 +129,13 @@ func TestCreateTaskDirectory(t *testing.T) {
 		// Attempt to create the directory
 		err := ts.CreateTaskDirectory(1, mismatchedFiles, true)
 		assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected ErrFailedValidateFiles error due to mismatched input/output files")
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode(), "expected a 400 status code for mismatched input/output files")
+		assert.Contains(t, err.Error(), "the number of input files must match the number of output files", "expected the specific reason in the error")
+
+		// Verify the existing task directory was left untouched
+		content, checkErr := os.ReadFile(filepath.Join(ts.taskDirectory, "task1", "src", "description.pdf"))
+		assert.NoError(t, checkErr, "expected the existing description.pdf to be kept")
+		assert.Equal(t, "New task description content", string(content), "description.pdf content should be unchanged")
 	})
 
 	// Subtest for files with invalid naming format
@@ -153,6 +164,87 @@ func TestCreateTaskDirectory(t *testing.T) {
 	})
 }
 
+func TestCreateTaskDirectoryPermissions(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	// Create a mock configuration with restrictive permissions
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+		DirMode:       0700,
+		FileMode:      0600,
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	files := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+
+	// Subtest for honoring the configured directory and file modes
+	t.Run("should create directories and files with the configured modes", func(t *testing.T) {
+		err := ts.CreateTaskDirectory(1, files, false)
+		assert.NoError(t, err, "expected no error when creating a new task directory")
+
+		srcDir := filepath.Join(ts.taskDirectory, "task1", "src")
+		for _, dir := range []string{srcDir, filepath.Join(srcDir, "input"), filepath.Join(srcDir, "output")} {
+			info, statErr := os.Stat(dir)
+			assert.NoError(t, statErr, "expected %s to exist", dir)
+			assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "expected %s to have the configured directory mode", dir)
+		}
+
+		for _, file := range []string{filepath.Join(srcDir, "description.pdf"), filepath.Join(srcDir, "input", "1.in")} {
+			info, statErr := os.Stat(file)
+			assert.NoError(t, statErr, "expected %s to exist", file)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "expected %s to have the configured file mode", file)
+		}
+	})
+}
+
+func TestValidateTaskFiles(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	// Create a mock configuration with the temporary root directory
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	// Subtest for a valid set of task files
+	t.Run("should accept a valid set of task files", func(t *testing.T) {
+		files := map[string][]byte{
+			"src/description.pdf": []byte("Task description content"),
+			"src/input/1.in":      []byte("Input file 1 content"),
+			"src/output/1.out":    []byte("Output file 1 content"),
+		}
+
+		err := ts.ValidateTaskFiles(files)
+		assert.NoError(t, err, "expected no error for a valid set of task files")
+	})
+
+	// Subtest for an invalid set of task files
+	t.Run("should return the specific reason without touching the disk", func(t *testing.T) {
+		files := map[string][]byte{
+			"src/input/1.in":   []byte("Input file 1 content"),
+			"src/output/1.out": []byte("Output file 1 content"),
+		}
+
+		err := ts.ValidateTaskFiles(files)
+		assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected ErrFailedValidateFiles when the description is missing")
+		assert.Contains(t, err.Error(), "a description file (description.pdf) is required", "expected the specific reason in the error")
+
+		// Verify nothing was written to the task directory
+		_, statErr := os.Stat(ts.taskDirectory)
+		assert.True(t, os.IsNotExist(statErr), "task directory should not be created by validation")
+	})
+}
+
 func TestCreateUserSubmission(t *testing.T) {
 	rootDir, cleanup := createTempRootDir(t)
 	defer cleanup()
@@ -287,6 +379,115 @@ func TestCreateUserSubmission(t *testing.T) {
 		assert.ErrorIs(t, err, ErrInvalidTaskID, "expected ErrInvalidTaskID error when trying to submit to a non-existent task")
 		assert.Equal(t, 0, submissionNumber, "expected submission number to be 0 on error")
 	})
+
+	// Subtest for preserving the original file name of a submission
+	t.Run("should preserve the original file name when enabled", func(t *testing.T) {
+		mockConfig.PreserveFileNames = true
+		defer func() { mockConfig.PreserveFileNames = false }()
+
+		userFileContent := []byte("int main() { return 0; }")
+		fileName := "MyProgram.cpp"
+
+		submissionNumber, err := ts.CreateUserSubmission(1, 3, userFileContent, fileName)
+		assert.NoError(t, err, "expected no error when creating a submission with a preserved file name")
+		assert.Equal(t, 1, submissionNumber, "expected first submission number to be 1")
+
+		// Verify the file is stored as solution{ext} for backward compatibility
+		submissionDir := filepath.Join(ts.taskDirectory, "task1", "submissions", "user3", "submission1")
+		assert.FileExists(t, filepath.Join(submissionDir, "solution.cpp"), "solution.cpp file should exist")
+
+		// Verify the original file name is returned when fetching the submission
+		content, returnedName, err := ts.GetUserSubmission(1, 3, submissionNumber)
+		assert.NoError(t, err, "expected no error when fetching the submission")
+		assert.Equal(t, fileName, returnedName, "expected the original file name to be returned")
+		assert.Equal(t, string(userFileContent), string(content), "submission content should match")
+	})
+}
+
+func TestCreateUserSubmissionFiles(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory:    rootDir,
+		AllowedFileTypes: []string{".cpp", ".h"},
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	taskFiles := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+	err := ts.CreateTaskDirectory(1, taskFiles, false)
+	assert.NoError(t, err, "expected no error when creating the task directory for task 1")
+
+	// Subtest for submitting several files together
+	t.Run("should store all submitted files together", func(t *testing.T) {
+		files := map[string][]byte{
+			"main.cpp": []byte("#include \"utils.h\"\nint main() { return 0; }"),
+			"utils.h":  []byte("int add(int a, int b);"),
+		}
+
+		submissionNumber, err := ts.CreateUserSubmissionFiles(1, 1, files)
+		assert.NoError(t, err, "expected no error when creating a multi-file submission")
+		assert.Equal(t, 1, submissionNumber, "expected first submission number to be 1")
+
+		submissionDir := filepath.Join(ts.taskDirectory, "task1", "submissions", "user1", "submission1")
+		assert.DirExists(t, filepath.Join(submissionDir, "output"), "output directory should exist")
+		for name, content := range files {
+			stored, checkErr := os.ReadFile(filepath.Join(submissionDir, name))
+			assert.NoError(t, checkErr, "expected %s to be stored", name)
+			assert.Equal(t, string(content), string(stored), "content of %s should match", name)
+		}
+	})
+
+	// Subtest for a file with a disallowed extension
+	t.Run("should return an error when one of the files has a disallowed extension", func(t *testing.T) {
+		files := map[string][]byte{
+			"main.cpp": []byte("int main() { return 0; }"),
+			"build.sh": []byte("g++ main.cpp"),
+		}
+
+		submissionNumber, err := ts.CreateUserSubmissionFiles(1, 2, files)
+		assert.ErrorIs(t, err, ErrFileExtensionNotAllowed, "expected ErrFileExtensionNotAllowed for a disallowed extension")
+		assert.Equal(t, 0, submissionNumber, "expected submission number to be 0 on error")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task1", "submissions", "user2", "submission1"), "no submission should be created")
+	})
+
+	// Subtest for a file name pointing outside the submission directory
+	t.Run("should return an error when a file name contains a path", func(t *testing.T) {
+		files := map[string][]byte{
+			"../main.cpp": []byte("int main() { return 0; }"),
+		}
+
+		_, err := ts.CreateUserSubmissionFiles(1, 3, files)
+		assert.ErrorIs(t, err, ErrInvalidSubmissionFileName, "expected ErrInvalidSubmissionFileName for a file name with a path")
+	})
+
+	// Subtest for an empty submission
+	t.Run("should return an error when no files are provided", func(t *testing.T) {
+		_, err := ts.CreateUserSubmissionFiles(1, 4, map[string][]byte{})
+		assert.ErrorIs(t, err, ErrNoSubmissionFiles, "expected ErrNoSubmissionFiles when no files are provided")
+	})
+
+	// Subtest for a file that cannot be written
+	t.Run("should remove the submission when a file cannot be saved", func(t *testing.T) {
+		files := map[string][]byte{
+			"main.cpp":                      []byte("int main() { return 0; }"),
+			strings.Repeat("a", 300) + ".h": []byte("int add(int a, int b);"),
+		}
+
+		_, err := ts.CreateUserSubmissionFiles(1, 5, files)
+		assert.ErrorIs(t, err, ErrFailedSaveUserFile, "expected ErrFailedSaveUserFile when a file cannot be written")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task1", "submissions", "user5", "submission1"), "the incomplete submission should be removed")
+
+		submissionNumber, err := ts.CreateUserSubmissionFiles(1, 5, map[string][]byte{"main.cpp": []byte("int main() { return 0; }")})
+		assert.NoError(t, err, "expected no error when creating the next submission")
+		assert.Equal(t, 1, submissionNumber, "expected the submission number of the failed submission to be reused")
+	})
 }
 
 func TestStoreUserOutputs(t *testing.T) {
@@ -340,7 +541,7 @@ func TestStoreUserOutputs(t *testing.T) {
 		}
 
 		// Store output files
-		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles)
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles, false)
 		assert.NoError(t, err, "expected no error when storing valid output files")
 
 		// Verify files are stored correctly
@@ -367,7 +568,7 @@ func TestStoreUserOutputs(t *testing.T) {
 		}
 
 		// Store compile error
-		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles)
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles, false)
 		assert.NoError(t, err, "expected no error when storing compile-error.err")
 
 		// Verify compile-error.err exists
@@ -393,7 +594,7 @@ func TestStoreUserOutputs(t *testing.T) {
 		}
 
 		// Attempt to store invalid output files
-		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles)
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles, false)
 		assert.ErrorIs(t, err, ErrInvalidOutputFileFormat, "expected ErrInvalidOutputFileFormat when storing files with the wrong format")
 	})
 
@@ -415,9 +616,112 @@ func TestStoreUserOutputs(t *testing.T) {
 		}
 
 		// Attempt to store the output files and expect an error
-		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles)
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, outputFiles, false)
 		assert.ErrorIs(t, err, ErrOutputFileMismatch, "expected ErrOutputFileMismatch error when the number of outputs does not match task's expected outputs")
 	})
+
+	// Subtest for refusing to replace stored outputs without overwrite
+	t.Run("should return an error when outputs are already stored and overwrite is false", func(t *testing.T) {
+		taskID := 7
+		userID := 1
+		submissionNumber := 1
+
+		createExpectedOutputFiles(taskID, 1)
+		createUserSubmissionDir(taskID, userID, submissionNumber)
+
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{"1.out": []byte("First run")}, false)
+		assert.NoError(t, err, "expected no error when storing the first outputs")
+
+		err = ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{"1.out": []byte("Second run")}, false)
+		assert.ErrorIs(t, err, ErrOutputDirContainsFiles, "expected ErrOutputDirContainsFiles when outputs are already stored")
+	})
+
+	// Subtest for re-storing outputs with overwrite
+	t.Run("should replace stored outputs when overwrite is true", func(t *testing.T) {
+		taskID := 8
+		userID := 1
+		submissionNumber := 1
+
+		createExpectedOutputFiles(taskID, 2)
+		createUserSubmissionDir(taskID, userID, submissionNumber)
+
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{
+			"1.out": []byte("First run 1"),
+			"2.out": []byte("First run 2"),
+			"1.err": []byte("First run stderr"),
+		}, false)
+		assert.NoError(t, err, "expected no error when storing the first outputs")
+
+		err = ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{
+			"1.out": []byte("Second run 1"),
+			"2.out": []byte("Second run 2"),
+		}, true)
+		assert.NoError(t, err, "expected no error when overwriting the outputs")
+
+		outputDir := filepath.Join(ts.taskDirectory, "task8", "submissions", "user1", "submission1", "output")
+		content, readErr := os.ReadFile(filepath.Join(outputDir, "1.out"))
+		assert.NoError(t, readErr, "expected no error reading the overwritten output")
+		assert.Equal(t, "Second run 1", string(content), "output should be replaced")
+		assert.NoFileExists(t, filepath.Join(outputDir, "1.err"), "outputs of the previous run should be cleared")
+	})
+
+	// Subtest for restoring the previous outputs when overwriting fails
+	t.Run("should restore the previous outputs when overwriting fails", func(t *testing.T) {
+		taskID := 9
+		userID := 1
+		submissionNumber := 1
+
+		createExpectedOutputFiles(taskID, 1)
+		createUserSubmissionDir(taskID, userID, submissionNumber)
+
+		err := ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{"1.out": []byte("First run")}, false)
+		assert.NoError(t, err, "expected no error when storing the first outputs")
+
+		err = ts.StoreUserOutputs(taskID, userID, submissionNumber, map[string][]byte{"2.out": []byte("Unexpected output")}, true)
+		assert.ErrorIs(t, err, ErrUnexpectedOutputFileNumber, "expected ErrUnexpectedOutputFileNumber for an output the task does not expect")
+
+		outputDir := filepath.Join(ts.taskDirectory, "task9", "submissions", "user1", "submission1", "output")
+		content, readErr := os.ReadFile(filepath.Join(outputDir, "1.out"))
+		assert.NoError(t, readErr, "expected the previous output to be restored")
+		assert.Equal(t, "First run", string(content), "previous output should be restored")
+		assert.NoFileExists(t, filepath.Join(outputDir, "2.out"), "new outputs should not be kept after a failure")
+	})
+
+	// Subtest for a task whose expected outputs use a configured pattern
+	t.Run("should store {number}.out outputs for a task with a configured output pattern", func(t *testing.T) {
+		patternConfig := &config.Config{
+			RootDirectory:     rootDir,
+			InputFilePattern:  `^input(\d+)\.txt$`,
+			OutputFilePattern: `^expected(\d+)\.txt$`,
+		}
+		ts := NewTaskService(patternConfig, taskutils.NewTaskUtils(patternConfig))
+
+		err := ts.CreateTaskDirectory(10, map[string][]byte{
+			"src/description.pdf":      []byte("Task description content"),
+			"src/input/input1.txt":     []byte("Input 1 content"),
+			"src/output/expected1.txt": []byte("Output 1 content"),
+			"src/input/input2.txt":     []byte("Input 2 content"),
+			"src/output/expected2.txt": []byte("Output 2 content"),
+		}, false)
+		assert.NoError(t, err, "expected no error when creating a task with the configured pattern")
+		createUserSubmissionDir(10, 1, 1)
+
+		err = ts.StoreUserOutputs(10, 1, 1, map[string][]byte{
+			"1.out": []byte("Output 1 content"),
+			"2.out": []byte("Output 2 content"),
+		}, false)
+		assert.NoError(t, err, "expected no error when storing {number}.out outputs for a task with a configured pattern")
+
+		outputDir := filepath.Join(ts.taskDirectory, "task10", "submissions", "user1", "submission1", "output")
+		assert.FileExists(t, filepath.Join(outputDir, "1.out"), "expected 1.out to be stored")
+		assert.FileExists(t, filepath.Join(outputDir, "2.out"), "expected 2.out to be stored")
+
+		err = ts.StoreUserOutputs(10, 1, 1, map[string][]byte{
+			"expected1.txt": []byte("Output 1 content"),
+			"expected2.txt": []byte("Output 2 content"),
+		}, true)
+		assert.ErrorIs(t, err, ErrOutputFileMismatch, "expected outputs named after the configured pattern to be rejected")
+	})
 }
 
 func TestGetTaskFiles(t *testing.T) {
@@ -502,6 +806,42 @@ func TestGetTaskFiles(t *testing.T) {
 		assert.ErrorIs(t, err, ErrTaskSrcDirDoesNotExist, "expected ErrTaskSrcDirDoesNotExist when src directory is missing")
 		assert.Empty(t, tarFilePath, "expected no tar file to be created when src directory is missing")
 	})
+
+	// Subtest for a configured compression level
+	t.Run("should create a valid .tar.gz with a configured compression level", func(t *testing.T) {
+		mockConfig.CompressionLevel = gzip.BestCompression
+		defer func() { mockConfig.CompressionLevel = 0 }()
+
+		taskID := 3
+		createSampleTaskDir(taskID)
+
+		tarFilePath, err := ts.GetTaskFiles(taskID)
+		assert.NoError(t, err, "expected no error when creating task archive")
+
+		// Verify the archive can be read back
+		tarFile, checkErr := os.Open(tarFilePath)
+		assert.NoError(t, checkErr, "failed to open created .tar.gz file")
+		defer utils.CloseIO(tarFile)
+
+		gzipReader, checkErr := gzip.NewReader(tarFile)
+		assert.NoError(t, checkErr, "failed to create gzip reader")
+		defer utils.CloseIO(gzipReader)
+
+		tarReader := tar.NewReader(gzipReader)
+		var content []byte
+		for {
+			header, checkErr := tarReader.Next()
+			if checkErr != nil {
+				assert.ErrorIs(t, checkErr, io.EOF, "expected the archive to be read until the end")
+				break
+			}
+			if header.Name == "task3Files/src/input/1.in" {
+				content, checkErr = io.ReadAll(tarReader)
+				assert.NoError(t, checkErr, "failed to read input file from the archive")
+			}
+		}
+		assert.Equal(t, "Input file 1 content", string(content), "expected the input file content to be preserved")
+	})
 }
 
 func TestGetUserSubmission(t *testing.T) {
@@ -590,6 +930,72 @@ func TestGetUserSubmission(t *testing.T) {
 		_, _, err = ts.GetUserSubmission(taskID, userID, submissionNum)
 		assert.ErrorIs(t, err, ErrMultipleProgramFilesFound, "expected ErrMultipleProgramFilesFound when multiple program files are found")
 	})
+
+	// Subtest: Retrieve the single file of a submission stored under its own name
+	t.Run("should retrieve a single file stored under its own name", func(t *testing.T) {
+		programContent := "int main() { return 0; }"
+
+		err := createSubmission(5, 1, 1, "main.c", programContent)
+		assert.NoError(t, err, "expected no error in creating submission directory")
+
+		content, fileName, err := ts.GetUserSubmission(5, 1, 1)
+		assert.NoError(t, err, "expected no error when retrieving the program file")
+		assert.Equal(t, "main.c", fileName, "file name should match the stored file")
+		assert.Equal(t, programContent, string(content), "program content should match")
+	})
+}
+
+func TestGetUserSubmissionFiles(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	// Subtest: Retrieve all files of a multi-file submission
+	t.Run("should retrieve all files of a submission", func(t *testing.T) {
+		submissionDir := filepath.Join(ts.taskDirectory, "task1", "submissions", "user1", "submission1")
+		err := os.MkdirAll(filepath.Join(submissionDir, "output"), os.ModePerm)
+		assert.NoError(t, err, "expected no error in creating submission directory")
+
+		files := map[string]string{
+			"main.c":  "#include \"utils.h\"\nint main() { return 0; }",
+			"utils.h": "int add(int a, int b);",
+		}
+		for name, content := range files {
+			err := os.WriteFile(filepath.Join(submissionDir, name), []byte(content), 0644)
+			assert.NoError(t, err, "expected no error in creating submission file %s", name)
+		}
+		err = os.WriteFile(filepath.Join(submissionDir, "output", "1.out"), []byte("output"), 0644)
+		assert.NoError(t, err, "expected no error in creating output file")
+
+		submissionFiles, err := ts.GetUserSubmissionFiles(1, 1, 1)
+		assert.NoError(t, err, "expected no error when retrieving the submission files")
+		assert.Len(t, submissionFiles, 2, "expected only the source files to be returned")
+		for name, content := range files {
+			assert.Equal(t, content, string(submissionFiles[name]), "content of %s should match", name)
+		}
+	})
+
+	// Subtest: Error when submission directory does not exist
+	t.Run("should return an error if submission directory does not exist", func(t *testing.T) {
+		_, err := ts.GetUserSubmissionFiles(2, 1, 1)
+		assert.ErrorIs(t, err, ErrSubmissionDirDoesNotExist, "expected ErrSubmissionDirDoesNotExist when submission directory does not exist")
+	})
+
+	// Subtest: Error when the submission contains no files
+	t.Run("should return an error if no files are found", func(t *testing.T) {
+		submissionDir := filepath.Join(ts.taskDirectory, "task3", "submissions", "user1", "submission1", "output")
+		err := os.MkdirAll(submissionDir, os.ModePerm)
+		assert.NoError(t, err, "expected no error in creating empty submission directory")
+
+		_, err = ts.GetUserSubmissionFiles(3, 1, 1)
+		assert.ErrorIs(t, err, ErrNoProgramFileFound, "expected ErrNoProgramFileFound when no files are found")
+	})
 }
 
 func TestGetInputOutput(t *testing.T) {
@@ -694,6 +1100,100 @@ func TestGetInputOutput(t *testing.T) {
 		_, err = ts.GetInputOutput(taskID, inputOutputID)
 		assert.ErrorIs(t, err, ErrOutputFileDoesNotExist, "expected ErrOutputFileDoesNotExist when output file is missing")
 	})
+
+	// Subtest for a configured input/output naming pattern
+	t.Run("should retrieve input and output files named with a configured pattern", func(t *testing.T) {
+		patternConfig := &config.Config{
+			RootDirectory:     rootDir,
+			InputFilePattern:  `^input(\d+)\.txt$`,
+			OutputFilePattern: `^expected(\d+)\.txt$`,
+		}
+		ts := NewTaskService(patternConfig, taskutils.NewTaskUtils(patternConfig))
+
+		files := map[string][]byte{
+			"src/description.pdf":      []byte("Task description content"),
+			"src/input/input1.txt":     []byte("Input 1 content"),
+			"src/output/expected1.txt": []byte("Output 1 content"),
+			"src/input/input2.txt":     []byte("Input 2 content"),
+			"src/output/expected2.txt": []byte("Output 2 content"),
+		}
+		err := ts.CreateTaskDirectory(4, files, false)
+		assert.NoError(t, err, "expected no error when creating a task with the configured pattern")
+
+		tarFilePath, err := ts.GetInputOutput(4, 2)
+		assert.NoError(t, err, "expected no error retrieving input/output files")
+
+		validateTarContents(t, tarFilePath, map[string]string{
+			"input2.txt":    "Input 2 content",
+			"expected2.txt": "Output 2 content",
+		})
+
+		pairs, err := ts.CountInputOutputPairs(4)
+		assert.NoError(t, err, "expected no error counting input/output pairs")
+		assert.Equal(t, 2, pairs, "expected both pairs to be counted")
+	})
+}
+
+func TestCountInputOutputPairs(t *testing.T) {
+	// Set up a temporary root directory
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	// Initialize TaskService with the mock configuration
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	// Helper function to create the given input and output files for a task
+	createFiles := func(taskID int, inputs, outputs []int) {
+		srcDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "src")
+		err := os.MkdirAll(filepath.Join(srcDir, "input"), os.ModePerm)
+		assert.NoError(t, err, "failed to create input directory")
+		err = os.MkdirAll(filepath.Join(srcDir, "output"), os.ModePerm)
+		assert.NoError(t, err, "failed to create output directory")
+
+		for _, i := range inputs {
+			err := os.WriteFile(filepath.Join(srcDir, "input", fmt.Sprintf("%d.in", i)), []byte("input"), 0644)
+			assert.NoError(t, err, "failed to create input file %d", i)
+		}
+		for _, i := range outputs {
+			err := os.WriteFile(filepath.Join(srcDir, "output", fmt.Sprintf("%d.out", i)), []byte("output"), 0644)
+			assert.NoError(t, err, "failed to create output file %d", i)
+		}
+	}
+
+	// Subtest for a complete set of pairs
+	t.Run("should count all pairs of a complete task", func(t *testing.T) {
+		createFiles(1, []int{1, 2, 3}, []int{1, 2, 3})
+
+		pairs, err := ts.CountInputOutputPairs(1)
+		assert.NoError(t, err, "expected no error counting pairs")
+		assert.Equal(t, 3, pairs, "expected three pairs")
+	})
+
+	// Subtest for a partial set of pairs
+	t.Run("should count only matching pairs of a partial task", func(t *testing.T) {
+		createFiles(2, []int{1, 2, 3}, []int{1, 3, 4})
+
+		pairs, err := ts.CountInputOutputPairs(2)
+		assert.NoError(t, err, "expected no error counting pairs")
+		assert.Equal(t, 2, pairs, "expected two pairs")
+	})
+
+	// Subtest for a missing task
+	t.Run("should return an error if the task does not exist", func(t *testing.T) {
+		_, err := ts.CountInputOutputPairs(999)
+		assert.ErrorIs(t, err, ErrInvalidTaskID, "expected ErrInvalidTaskID for a missing task")
+	})
+
+	// Subtest for the pair count in GetInputOutput errors
+	t.Run("should include the pair count when requesting a missing pair", func(t *testing.T) {
+		_, err := ts.GetInputOutput(1, 5)
+		assert.ErrorIs(t, err, ErrInputFileDoesNotExist, "expected ErrInputFileDoesNotExist for a missing pair")
+		assert.Contains(t, err.Error(), "task 1 has 3 input/output pairs", "expected the pair count in the error")
+	})
 }
 
 func TestDeleteTask(t *testing.T) {
@@ -747,6 +1247,7 @@ func TestDeleteTask(t *testing.T) {
 		// Call DeleteTask on a non-existent directory
 		err := ts.DeleteTask(taskID)
 		assert.ErrorIs(t, err, ErrInvalidTaskID, "expected ErrInvalidTaskID when the task directory does not exist")
+		assert.Equal(t, http.StatusNotFound, err.StatusCode(), "expected a 404 status code for a missing task")
 	})
 
 	// Test for handling directory deletion failure due to permissions
@@ -854,6 +1355,60 @@ func TestGetUserSolutionPackage(t *testing.T) {
 		validateTarContents(t, tarFilePath, expectedFiles)
 	})
 
+	// Subtest for a submission with a preserved file name
+	t.Run("should use the preserved name of the solution file", func(t *testing.T) {
+		taskID := 5
+		userID := 1
+		submissionNum := 1
+
+		err := createTaskFiles(taskID, userID, submissionNum)
+		assert.NoError(t, err, "expected no error in creating task files")
+
+		solutionDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNum))
+		err = os.WriteFile(filepath.Join(solutionDir, submissionMetadataFile), []byte(`{"originalFileName":"Main.c"}`), 0644)
+		assert.NoError(t, err, "expected no error in creating the submission metadata")
+
+		tarFilePath, err := ts.GetUserSolutionPackage(taskID, userID, submissionNum)
+		assert.NoError(t, err, "expected no error fetching user solution package")
+
+		validateTarContents(t, tarFilePath, map[string]string{
+			"Task/inputs/1.in": "input content 1",
+			"Task/Main.c":      "solution content",
+		})
+	})
+
+	// Subtest for a submission made of several files
+	t.Run("should include all files of a multi-file submission", func(t *testing.T) {
+		multiFileConfig := &config.Config{
+			RootDirectory:    rootDir,
+			AllowedFileTypes: []string{".cpp", ".h"},
+		}
+		multiFileTs := NewTaskService(multiFileConfig, taskutils.NewTaskUtils(multiFileConfig))
+
+		err := multiFileTs.CreateTaskDirectory(6, map[string][]byte{
+			"src/description.pdf": []byte("Task description content"),
+			"src/input/1.in":      []byte("input content 1"),
+			"src/output/1.out":    []byte("output content 1"),
+		}, false)
+		assert.NoError(t, err, "expected no error when creating the task directory")
+
+		submissionNumber, err := multiFileTs.CreateUserSubmissionFiles(6, 1, map[string][]byte{
+			"main.cpp": []byte("#include \"utils.h\"\nint main() { return 0; }"),
+			"utils.h":  []byte("int add(int a, int b);"),
+		})
+		assert.NoError(t, err, "expected no error when creating a multi-file submission")
+
+		tarFilePath, err := multiFileTs.GetUserSolutionPackage(6, 1, submissionNumber)
+		assert.NoError(t, err, "expected no error fetching user solution package")
+
+		validateTarContents(t, tarFilePath, map[string]string{
+			"Task/inputs/1.in":   "input content 1",
+			"Task/outputs/1.out": "output content 1",
+			"Task/main.cpp":      "#include \"utils.h\"\nint main() { return 0; }",
+			"Task/utils.h":       "int add(int a, int b);",
+		})
+	})
+
 	// Subtest for missing input directory
 	t.Run("should return an error if input directory is missing", func(t *testing.T) {
 		taskID := 2
@@ -959,6 +1514,7 @@ func TestGetTaskDescription(t *testing.T) {
 		// Attempt to retrieve a description file from a non-existent task directory
 		_, _, err := ts.GetTaskDescription(taskID)
 		assert.ErrorIs(t, err, ErrDescriptionFileDoesNotExist, "expected ErrDescriptionFileDoesNotExist when task directory does not exist")
+		assert.Equal(t, http.StatusNotFound, err.StatusCode(), "expected a 404 status code for a missing description")
 	})
 
 	// Subtest: Error when description file does not exist in the task directory
@@ -976,6 +1532,140 @@ func TestGetTaskDescription(t *testing.T) {
 	})
 }
 
+func TestGetTaskDescriptionStream(t *testing.T) {
+	// Create a temporary root directory for tests
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	// Subtest for streaming an existing description file
+	t.Run("should stream the description file for a valid task", func(t *testing.T) {
+		taskID := 1
+		descriptionContent := bytes.Repeat([]byte("%PDF-1.4 description "), 1024)
+
+		srcDir := filepath.Join(ts.taskDirectory, fmt.Sprintf("task%d", taskID), "src")
+		err := os.MkdirAll(srcDir, os.ModePerm)
+		assert.NoError(t, err, "expected no error in creating task src directory")
+		err = os.WriteFile(filepath.Join(srcDir, "description.pdf"), descriptionContent, 0644)
+		assert.NoError(t, err, "expected no error in creating task description file")
+
+		reader, fileName, serviceErr := ts.GetTaskDescriptionStream(taskID)
+		assert.NoError(t, serviceErr, "expected no error when opening the description file")
+		assert.Equal(t, "description.pdf", fileName, "filename should be 'description.pdf'")
+		defer utils.CloseIO(reader)
+
+		streamed, err := io.ReadAll(reader)
+		assert.NoError(t, err, "expected no error reading the description stream")
+		assert.Equal(t, descriptionContent, streamed, "streamed content should match the description file")
+	})
+
+	// Subtest for a task without a description file
+	t.Run("should return an error if description file does not exist", func(t *testing.T) {
+		reader, _, err := ts.GetTaskDescriptionStream(2)
+		assert.Nil(t, reader, "expected no reader for a missing description")
+		assert.ErrorIs(t, err, ErrDescriptionFileDoesNotExist, "expected ErrDescriptionFileDoesNotExist when description file is missing")
+	})
+}
+
+func TestArchiveRoot(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory:    rootDir,
+		AllowedFileTypes: []string{".c"},
+		ArchiveRoot:      "task",
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	files := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+	err := ts.CreateTaskDirectory(1, files, false)
+	assert.NoError(t, err, "expected no error when creating the task directory")
+
+	submissionNumber, err := ts.CreateUserSubmission(1, 1, []byte("int main() { return 0; }"), "main.c")
+	assert.NoError(t, err, "expected no error when creating a submission")
+
+	// Helper function to list the names of the files in a tar.gz archive
+	listTarFiles := func(tarFilePath string) []string {
+		tarFile, err := os.Open(tarFilePath)
+		assert.NoError(t, err, "expected no error opening tar.gz file")
+		defer utils.CloseIO(tarFile)
+
+		gzipReader, err := gzip.NewReader(tarFile)
+		assert.NoError(t, err, "expected no error creating gzip reader")
+		defer utils.CloseIO(gzipReader)
+
+		names := make([]string, 0)
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err, "expected no error reading tar header")
+			if header.Typeflag == tar.TypeReg {
+				names = append(names, header.Name)
+			}
+		}
+		return names
+	}
+
+	// Subtest for the task files archive
+	t.Run("should place the task files under the configured root", func(t *testing.T) {
+		archivePath, err := ts.GetTaskFiles(1)
+		assert.NoError(t, err, "expected no error when getting the task files")
+		defer utils.RemoveDirectory(archivePath)
+
+		assert.ElementsMatch(t, []string{
+			"task/src/description.pdf",
+			"task/src/input/1.in",
+			"task/src/output/1.out",
+		}, listTarFiles(archivePath), "task files should be under the configured root")
+	})
+
+	// Subtest for the input/output archive
+	t.Run("should place the input/output pair under the configured root", func(t *testing.T) {
+		archivePath, err := ts.GetInputOutput(1, 1)
+		assert.NoError(t, err, "expected no error when getting the input/output pair")
+		defer utils.RemoveDirectory(archivePath)
+
+		assert.ElementsMatch(t, []string{"task/1.in", "task/1.out"}, listTarFiles(archivePath), "input/output pair should be under the configured root")
+	})
+
+	// Subtest for the solution package archive
+	t.Run("should place the solution package under the configured root", func(t *testing.T) {
+		archivePath, err := ts.GetUserSolutionPackage(1, 1, submissionNumber)
+		assert.NoError(t, err, "expected no error when getting the solution package")
+		defer utils.RemoveDirectory(archivePath)
+
+		assert.ElementsMatch(t, []string{
+			"task/inputs/1.in",
+			"task/outputs/1.out",
+			"task/solution.c",
+		}, listTarFiles(archivePath), "solution package should be under the configured root")
+	})
+
+	// Subtest for the submission archive
+	t.Run("should place the submission under the configured root", func(t *testing.T) {
+		archivePath, err := ts.ArchiveSubmission(1, 1, submissionNumber, ArchiveFormatTarGz)
+		assert.NoError(t, err, "expected no error when archiving the submission")
+		defer utils.RemoveDirectory(archivePath)
+
+		assert.ElementsMatch(t, []string{"task/solution.c"}, listTarFiles(archivePath), "submission should be under the configured root")
+	})
+}
+
 // Helper function to validate the tar.gz contents
 func validateTarContents(t *testing.T, tarFilePath string, expectedFiles map[string]string) {
 	tarFile, err := os.Open(tarFilePath)
@@ -1005,4 +1695,495 @@ func validateTarContents(t *testing.T, tarFilePath string, expectedFiles map[str
 	for path, expectedContent := range expectedFiles {
 		assert.Equal(t, expectedContent, foundFiles[path], fmt.Sprintf("file content for %s should match expected", path))
 	}
-}
\ No newline at end of file
+}
+func TestListAllUserSubmissions(t *testing.T) {
+	// Create a temporary root directory for tests
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	// Create a mock configuration with the temporary root directory
+	mockConfig := &config.Config{
+		RootDirectory:    rootDir,
+		AllowedFileTypes: []string{".c"},
+	}
+
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	taskFiles := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+
+	// Subtest: No tasks yet
+	t.Run("should return an empty list when there are no tasks", func(t *testing.T) {
+		submissions, err := ts.ListAllUserSubmissions(1)
+		assert.NoError(t, err, "expected no error when the tasks directory does not exist")
+		assert.Empty(t, submissions, "expected no submissions")
+	})
+
+	// Subtest: Submissions across several tasks
+	t.Run("should list the user's submissions across all tasks", func(t *testing.T) {
+		for _, taskID := range []int{1, 2, 3} {
+			err := ts.CreateTaskDirectory(taskID, taskFiles, false)
+			assert.NoError(t, err, "expected no error creating task %d", taskID)
+		}
+
+		// User 1 submits twice to task 2 and once to task 1, user 2 submits to task 3
+		for _, submission := range []struct{ taskID, userID int }{{2, 1}, {1, 1}, {2, 1}, {3, 2}} {
+			_, err := ts.CreateUserSubmission(submission.taskID, submission.userID, []byte("int main() { return 0; }"), "solution.c")
+			assert.NoError(t, err, "expected no error creating submission")
+		}
+
+		submissions, err := ts.ListAllUserSubmissions(1)
+		assert.NoError(t, err, "expected no error listing user submissions")
+		assert.Len(t, submissions, 3, "expected three submissions for user 1")
+
+		expected := []struct{ taskID, submissionNumber int }{{1, 1}, {2, 1}, {2, 2}}
+		for i, submission := range submissions {
+			assert.Equal(t, expected[i].taskID, submission.TaskID, "unexpected task ID at position %d", i)
+			assert.Equal(t, expected[i].submissionNumber, submission.SubmissionNumber, "unexpected submission number at position %d", i)
+			assert.False(t, submission.Timestamp.IsZero(), "expected a submission timestamp")
+		}
+	})
+}
+
+func TestCopyTask(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory:    rootDir,
+		AllowedFileTypes: []string{".c"},
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	files := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+	err := ts.CreateTaskDirectory(1, files, false)
+	assert.NoError(t, err, "expected no error when creating the source task")
+	_, err = ts.CreateUserSubmission(1, 1, []byte("int main() { return 0; }"), "solution.c")
+	assert.NoError(t, err, "expected no error when creating a submission for the source task")
+
+	// Subtest for cloning a task
+	t.Run("should copy the task files without the submissions", func(t *testing.T) {
+		err := ts.CopyTask(1, 2, false)
+		assert.NoError(t, err, "expected no error when copying the task")
+
+		dstSrcDir := filepath.Join(ts.taskDirectory, "task2", "src")
+		for name, content := range files {
+			copied, checkErr := os.ReadFile(filepath.Join(ts.taskDirectory, "task2", name))
+			assert.NoError(t, checkErr, "expected %s to be copied", name)
+			assert.Equal(t, string(content), string(copied), "content of %s should match", name)
+		}
+		assert.DirExists(t, dstSrcDir, "src directory should exist")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task2", "submissions"), "submissions should not be copied")
+	})
+
+	// Subtest for an existing destination without overwrite
+	t.Run("should return an error when the destination exists and overwrite is false", func(t *testing.T) {
+		err := ts.CopyTask(1, 2, false)
+		assert.ErrorIs(t, err, ErrDirectoryAlreadyExists, "expected ErrDirectoryAlreadyExists error")
+	})
+
+	// Subtest for overwriting an existing destination
+	t.Run("should overwrite the destination when overwrite is true", func(t *testing.T) {
+		descriptionFile := filepath.Join(ts.taskDirectory, "task2", "src", "description.pdf")
+		err := os.WriteFile(descriptionFile, []byte("Changed description"), 0644)
+		assert.NoError(t, err, "failed to change the destination description")
+
+		err = ts.CopyTask(1, 2, true)
+		assert.NoError(t, err, "expected no error when overwriting the destination")
+
+		content, checkErr := os.ReadFile(descriptionFile)
+		assert.NoError(t, checkErr, "expected no error reading description.pdf")
+		assert.Equal(t, "Task description content", string(content), "description.pdf should be copied from the source")
+	})
+
+	// Subtest for a missing source task
+	t.Run("should return an error when the source task does not exist", func(t *testing.T) {
+		err := ts.CopyTask(99, 3, false)
+		assert.ErrorIs(t, err, ErrInvalidTaskID, "expected ErrInvalidTaskID when the source task does not exist")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task3"), "no destination should be created")
+	})
+}
+
+// Helper function to create a .tar.gz task archive with the given files, names ending with "/" are directories
+func createTaskArchive(t *testing.T, files map[string]string) string {
+	archivePath := filepath.Join(t.TempDir(), "task.tar.gz")
+	archiveFile, err := os.Create(archivePath)
+	assert.NoError(t, err, "failed to create archive file")
+	defer utils.CloseIO(archiveFile)
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	defer utils.CloseIO(gzipWriter)
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer utils.CloseIO(tarWriter)
+
+	for name, content := range files {
+		if strings.HasSuffix(name, "/") {
+			err := tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0755})
+			assert.NoError(t, err, "failed to write tar header")
+			continue
+		}
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+		assert.NoError(t, err, "failed to write tar header")
+		_, err = tarWriter.Write([]byte(content))
+		assert.NoError(t, err, "failed to write tar content")
+	}
+	return archivePath
+}
+
+func TestCreateTaskDirectoryFromArchive(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	validFiles := map[string]string{
+		"Task/description.pdf": "Task description content",
+		"Task/input/1.in":      "Input file 1 content",
+		"Task/output/1.out":    "Output file 1 content",
+	}
+
+	// Subtest for creating a task from a valid archive
+	t.Run("should create a task directory from an archive", func(t *testing.T) {
+		err := ts.CreateTaskDirectoryFromArchive(1, createTaskArchive(t, validFiles), false)
+		assert.NoError(t, err, "expected no error when creating a task from an archive")
+
+		srcDir := filepath.Join(ts.taskDirectory, "task1", "src")
+		for name, content := range map[string]string{
+			"description.pdf": "Task description content",
+			"input/1.in":      "Input file 1 content",
+			"output/1.out":    "Output file 1 content",
+		} {
+			stored, checkErr := os.ReadFile(filepath.Join(srcDir, name))
+			assert.NoError(t, checkErr, "expected %s to exist", name)
+			assert.Equal(t, content, string(stored), "content of %s should match", name)
+		}
+	})
+
+	// Subtest for an existing task without overwrite
+	t.Run("should return an error when the directory exists and overwrite is false", func(t *testing.T) {
+		err := ts.CreateTaskDirectoryFromArchive(1, createTaskArchive(t, validFiles), false)
+		assert.ErrorIs(t, err, ErrDirectoryAlreadyExists, "expected ErrDirectoryAlreadyExists error")
+	})
+
+	// Subtest for an archive with an invalid layout
+	t.Run("should return an error and keep the existing task when the archive is invalid", func(t *testing.T) {
+		invalidFiles := map[string]string{
+			"Task/description.pdf": "New description",
+			"Task/input/1.in":      "Input file 1 content",
+		}
+
+		err := ts.CreateTaskDirectoryFromArchive(1, createTaskArchive(t, invalidFiles), true)
+		assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected ErrFailedValidateFiles for mismatched input/output files")
+
+		content, checkErr := os.ReadFile(filepath.Join(ts.taskDirectory, "task1", "src", "description.pdf"))
+		assert.NoError(t, checkErr, "expected the existing description.pdf to be kept")
+		assert.Equal(t, "Task description content", string(content), "description.pdf content should be unchanged")
+	})
+
+	// Subtest for an archive without a single main folder
+	t.Run("should return an error when the archive has more than one main folder", func(t *testing.T) {
+		files := map[string]string{
+			"Task/description.pdf":  "Task description content",
+			"Other/description.pdf": "Task description content",
+		}
+
+		err := ts.CreateTaskDirectoryFromArchive(2, createTaskArchive(t, files), false)
+		assert.ErrorIs(t, err, ErrInvalidTaskArchive, "expected ErrInvalidTaskArchive for an archive with two main folders")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task2"), "no task directory should be created")
+	})
+
+	// Subtest for an archive entry escaping the extraction directory
+	t.Run("should return an error when an archive entry escapes the extraction directory", func(t *testing.T) {
+		files := map[string]string{
+			"Task/description.pdf":             "Task description content",
+			"Task/input/1.in":                  "Input file 1 content",
+			"Task/output/1.out":                "Output file 1 content",
+			"../evil_task_archive_entry":       "evil",
+			"Task/../../evil_task_archive_dir": "evil",
+		}
+
+		err := ts.CreateTaskDirectoryFromArchive(3, createTaskArchive(t, files), false)
+		assert.ErrorIs(t, err, ErrFailedDecompressArchive, "expected ErrFailedDecompressArchive for an entry escaping the extraction directory")
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), "evil_task_archive_entry"), "no file should be written outside the extraction directory")
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), "evil_task_archive_dir"), "no file should be written outside the extraction directory")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task3"), "no task directory should be created")
+	})
+
+	// Subtest for an archive without the input and output directories
+	t.Run("should return an error when the input and output directories are missing", func(t *testing.T) {
+		files := map[string]string{
+			"Task/description.pdf": "Task description content",
+		}
+
+		err := ts.CreateTaskDirectoryFromArchive(4, createTaskArchive(t, files), false)
+		assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected ErrFailedValidateFiles for an archive without input and output directories")
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task4"), "no task directory should be created")
+	})
+
+	// Subtest for honoring the configured directory and file modes
+	t.Run("should create directories and files with the configured modes", func(t *testing.T) {
+		restrictiveConfig := &config.Config{
+			RootDirectory: rootDir,
+			DirMode:       0700,
+			FileMode:      0600,
+		}
+		restrictiveTs := NewTaskService(restrictiveConfig, taskutils.NewTaskUtils(restrictiveConfig))
+
+		err := restrictiveTs.CreateTaskDirectoryFromArchive(5, createTaskArchive(t, validFiles), false)
+		assert.NoError(t, err, "expected no error when creating a task from an archive")
+
+		srcDir := filepath.Join(restrictiveTs.taskDirectory, "task5", "src")
+		for _, dir := range []string{srcDir, filepath.Join(srcDir, "input"), filepath.Join(srcDir, "output")} {
+			info, statErr := os.Stat(dir)
+			assert.NoError(t, statErr, "expected %s to exist", dir)
+			assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "expected %s to have the configured directory mode", dir)
+		}
+
+		for _, file := range []string{filepath.Join(srcDir, "description.pdf"), filepath.Join(srcDir, "input", "1.in")} {
+			info, statErr := os.Stat(file)
+			assert.NoError(t, statErr, "expected %s to exist", file)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "expected %s to have the configured file mode", file)
+		}
+	})
+}
+
+func TestValidateTaskArchive(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory: rootDir,
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	// Subtest for a valid archive
+	t.Run("should accept a valid archive without creating the task", func(t *testing.T) {
+		files := map[string]string{
+			"Task/description.pdf": "Task description content",
+			"Task/input/1.in":      "Input file 1 content",
+			"Task/output/1.out":    "Output file 1 content",
+		}
+
+		err := ts.ValidateTaskArchive(createTaskArchive(t, files))
+		assert.NoError(t, err, "expected no error when validating a valid archive")
+
+		assert.NoDirExists(t, filepath.Join(ts.taskDirectory, "task1"), "no task directory should be created")
+	})
+
+	// Subtest for an archive with empty input and output directories
+	t.Run("should accept an archive with empty input and output directories", func(t *testing.T) {
+		files := map[string]string{
+			"Task/description.pdf": "Task description content",
+			"Task/input/":          "",
+			"Task/output/":         "",
+		}
+
+		err := ts.ValidateTaskArchive(createTaskArchive(t, files))
+		assert.NoError(t, err, "expected no error when the input and output directories are empty")
+	})
+
+	// Subtests for archives rejected by CreateTaskDirectoryFromArchive as well
+	for name, files := range map[string]map[string]string{
+		"an extra file": {
+			"Task/description.pdf": "Task description content",
+			"Task/input/1.in":      "Input file 1 content",
+			"Task/output/1.out":    "Output file 1 content",
+			"Task/notes.txt":       "Extra file",
+		},
+		"an extra directory": {
+			"Task/description.pdf": "Task description content",
+			"Task/input/1.in":      "Input file 1 content",
+			"Task/output/1.out":    "Output file 1 content",
+			"Task/tests/1.in":      "Extra file",
+		},
+		"a missing input directory": {
+			"Task/description.pdf": "Task description content",
+			"Task/output/":         "",
+		},
+		"a missing output directory": {
+			"Task/description.pdf": "Task description content",
+			"Task/input/":          "",
+		},
+	} {
+		t.Run("should reject an archive with "+name, func(t *testing.T) {
+			archivePath := createTaskArchive(t, files)
+
+			err := ts.ValidateTaskArchive(archivePath)
+			assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected ErrFailedValidateFiles")
+
+			err = ts.CreateTaskDirectoryFromArchive(1, archivePath, false)
+			assert.ErrorIs(t, err, ErrFailedValidateFiles, "expected CreateTaskDirectoryFromArchive to reject the archive too")
+		})
+	}
+}
+
+func TestGetSubmissionCount(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory:    rootDir,
+		AllowedFileTypes: []string{".c"},
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	files := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+
+	// Subtest for counting the submissions of several users
+	t.Run("should sum the submissions of all users", func(t *testing.T) {
+		err := ts.CreateTaskDirectory(1, files, false)
+		assert.NoError(t, err, "expected no error when creating the task directory")
+
+		for _, userID := range []int{1, 1, 2, 3, 3, 3} {
+			_, err := ts.CreateUserSubmission(1, userID, []byte("int main() { return 0; }"), "solution.c")
+			assert.NoError(t, err, "expected no error when creating a submission")
+		}
+
+		count, err := ts.GetSubmissionCount(1)
+		assert.NoError(t, err, "expected no error when counting submissions")
+		assert.Equal(t, 6, count, "expected the submissions of all users to be counted")
+	})
+
+	// Subtest for a task with an empty submissions directory
+	t.Run("should return 0 for a task without submissions", func(t *testing.T) {
+		err := ts.CreateTaskDirectory(2, files, false)
+		assert.NoError(t, err, "expected no error when creating the task directory")
+		checkErr := os.MkdirAll(filepath.Join(ts.taskDirectory, "task2", "submissions"), os.ModePerm)
+		assert.NoError(t, checkErr, "expected no error when creating the submissions directory")
+
+		count, err := ts.GetSubmissionCount(2)
+		assert.NoError(t, err, "expected no error for a task without submissions")
+		assert.Equal(t, 0, count, "expected no submissions to be counted")
+	})
+
+	// Subtest for a missing task
+	t.Run("should return an error when the task does not exist", func(t *testing.T) {
+		_, err := ts.GetSubmissionCount(99)
+		assert.ErrorIs(t, err, ErrInvalidTaskID, "expected ErrInvalidTaskID when the task does not exist")
+	})
+}
+
+func TestArchiveSubmission(t *testing.T) {
+	rootDir, cleanup := createTempRootDir(t)
+	defer cleanup()
+
+	mockConfig := &config.Config{
+		RootDirectory:     rootDir,
+		AllowedFileTypes:  []string{".c"},
+		PreserveFileNames: true,
+	}
+	tu := taskutils.NewTaskUtils(mockConfig)
+	ts := NewTaskService(mockConfig, tu)
+
+	files := map[string][]byte{
+		"src/description.pdf": []byte("Task description content"),
+		"src/input/1.in":      []byte("Input file 1 content"),
+		"src/output/1.out":    []byte("Output file 1 content"),
+	}
+	err := ts.CreateTaskDirectory(1, files, false)
+	assert.NoError(t, err, "expected no error when creating the task directory")
+
+	// Helper function to create a submission with the given output files
+	createSubmission := func(userID int, outputs map[string]string) int {
+		submissionNumber, err := ts.CreateUserSubmission(1, userID, []byte("int main() { return 0; }"), "main.c")
+		assert.NoError(t, err, "expected no error when creating a submission")
+
+		outputDir := filepath.Join(ts.taskDirectory, "task1", "submissions", fmt.Sprintf("user%d", userID), fmt.Sprintf("submission%d", submissionNumber), "output")
+		for name, content := range outputs {
+			checkErr := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0644)
+			assert.NoError(t, checkErr, "failed to create output file %s", name)
+		}
+		return submissionNumber
+	}
+
+	// Subtest for a submission with outputs
+	t.Run("should archive the source file and the outputs", func(t *testing.T) {
+		submissionNumber := createSubmission(1, map[string]string{"1.out": "Output 1", "1.err": "Stderr 1"})
+
+		archivePath, err := ts.ArchiveSubmission(1, 1, submissionNumber, ArchiveFormatTarGz)
+		assert.NoError(t, err, "expected no error when archiving the submission")
+		defer utils.RemoveDirectory(archivePath)
+
+		validateTarContents(t, archivePath, map[string]string{
+			"Submission/main.c":       "int main() { return 0; }",
+			"Submission/output/1.out": "Output 1",
+			"Submission/output/1.err": "Stderr 1",
+		})
+	})
+
+	// Subtest for a submission that failed to compile, archived as zip
+	t.Run("should archive the compile error as a zip", func(t *testing.T) {
+		submissionNumber := createSubmission(2, map[string]string{"compile-error.err": "error: expected ';'"})
+
+		archivePath, err := ts.ArchiveSubmission(1, 2, submissionNumber, ArchiveFormatZip)
+		assert.NoError(t, err, "expected no error when archiving the submission")
+		defer utils.RemoveDirectory(archivePath)
+
+		zipReader, checkErr := zip.OpenReader(archivePath)
+		assert.NoError(t, checkErr, "expected a valid zip archive")
+		defer utils.CloseIO(zipReader)
+
+		foundFiles := make(map[string]string)
+		for _, file := range zipReader.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			rc, checkErr := file.Open()
+			assert.NoError(t, checkErr, "failed to open %s", file.Name)
+			content, checkErr := io.ReadAll(rc)
+			assert.NoError(t, checkErr, "failed to read %s", file.Name)
+			utils.CloseIO(rc)
+			foundFiles[file.Name] = string(content)
+		}
+
+		assert.Equal(t, map[string]string{
+			"Submission/main.c":                   "int main() { return 0; }",
+			"Submission/output/compile-error.err": "error: expected ';'",
+		}, foundFiles, "expected the source file under its preserved name and the compile error without the metadata")
+	})
+
+	// Subtest for exporting the same submission twice
+	t.Run("should create a separate archive for every export", func(t *testing.T) {
+		firstPath, err := ts.ArchiveSubmission(1, 1, 1, ArchiveFormatTarGz)
+		assert.NoError(t, err, "expected no error when archiving the submission")
+		defer utils.RemoveDirectory(firstPath)
+
+		secondPath, err := ts.ArchiveSubmission(1, 1, 1, ArchiveFormatTarGz)
+		assert.NoError(t, err, "expected no error when archiving the submission again")
+		defer utils.RemoveDirectory(secondPath)
+
+		assert.NotEqual(t, firstPath, secondPath, "expected every export to use its own archive file")
+		validateTarContents(t, firstPath, map[string]string{"Submission/main.c": "int main() { return 0; }"})
+	})
+
+	// Subtest for a missing submission
+	t.Run("should return an error when the submission does not exist", func(t *testing.T) {
+		_, err := ts.ArchiveSubmission(1, 99, 1, ArchiveFormatTarGz)
+		assert.ErrorIs(t, err, ErrSubmissionDirDoesNotExist, "expected ErrSubmissionDirDoesNotExist for a missing submission")
+	})
+
+	// Subtest for an unsupported format
+	t.Run("should return an error for an unsupported format", func(t *testing.T) {
+		_, err := ts.ArchiveSubmission(1, 1, 1, ArchiveFormat(".rar"))
+		assert.ErrorIs(t, err, ErrUnsupportedArchiveFormat, "expected ErrUnsupportedArchiveFormat for an unsupported format")
+	})
+}
@@ -14,15 +14,36 @@ import (
 	"github.com/mini-maxit/file-storage/internal/config"
 )
 
+// defaultInputPattern and defaultOutputPattern match the default {number}.in and {number}.out file names.
+var (
+	defaultInputPattern  = regexp.MustCompile(config.DefaultInputFilePattern)
+	defaultOutputPattern = regexp.MustCompile(config.DefaultOutputFilePattern)
+)
+
 type TaskUtils struct {
 	Config *config.Config
+
+	// inputPattern and outputPattern are compiled once from the configuration, see InputPattern and OutputPattern
+	inputPattern  *regexp.Regexp
+	outputPattern *regexp.Regexp
 }
 
 // NewTaskUtils creates a new instance of TaskUtils with the provided configuration.
+// The input and output file name patterns are compiled once here. They are validated when the configuration
+// is loaded, so an invalid pattern set directly on the configuration panics.
 func NewTaskUtils(cfg *config.Config) *TaskUtils {
-	return &TaskUtils{
-		Config: cfg,
+	tu := &TaskUtils{
+		Config:        cfg,
+		inputPattern:  defaultInputPattern,
+		outputPattern: defaultOutputPattern,
+	}
+	if cfg != nil && cfg.InputFilePattern != "" {
+		tu.inputPattern = regexp.MustCompile(cfg.InputFilePattern)
+	}
+	if cfg != nil && cfg.OutputFilePattern != "" {
+		tu.outputPattern = regexp.MustCompile(cfg.OutputFilePattern)
 	}
+	return tu
 }
 
 // BackupDirectory creates a backup of an existing directory in a temporary location.
@@ -104,21 +125,83 @@ func (tu *TaskUtils) CopyFile(src, dst string) error {
 	return os.Chmod(dst, info.Mode())
 }
 
+// InputPattern returns the regular expression input file names must match, with the file number captured
+// by the first group. It falls back to the {number}.in scheme if no valid pattern is configured.
+func (tu *TaskUtils) InputPattern() *regexp.Regexp {
+	if tu.inputPattern == nil {
+		return defaultInputPattern
+	}
+	return tu.inputPattern
+}
+
+// OutputPattern returns the regular expression output file names must match, with the file number captured
+// by the first group. It falls back to the {number}.out scheme if no valid pattern is configured.
+func (tu *TaskUtils) OutputPattern() *regexp.Regexp {
+	if tu.outputPattern == nil {
+		return defaultOutputPattern
+	}
+	return tu.outputPattern
+}
+
+// NumberedFiles returns the files of the directory whose names match the pattern, keyed by their number.
+func (tu *TaskUtils) NumberedFiles(dir string, pattern *regexp.Regexp) (map[int]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := pattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		num, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		files[num] = filepath.Join(dir, entry.Name())
+	}
+
+	return files, nil
+}
+
 // CreateDirectoryStructure creates the required directory structure for a task.
 func (tu *TaskUtils) CreateDirectoryStructure(srcDir, inputDir, outputDir string) error {
-	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(srcDir, tu.Config.DirPerm()); err != nil {
 		return fmt.Errorf("failed to create src directory: %v", err)
 	}
-	if err := os.MkdirAll(inputDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(inputDir, tu.Config.DirPerm()); err != nil {
 		return fmt.Errorf("failed to create input directory: %v", err)
 	}
-	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(outputDir, tu.Config.DirPerm()); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
 	}
 	return nil
 }
 
-// ValidateFiles checks if input and output files have names in the correct format ({number}.in or {number}.out),
+// SetPermissions applies the configured directory and file modes to dir and everything below it.
+func (tu *TaskUtils) SetPermissions(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		mode := tu.Config.FilePerm()
+		if info.IsDir() {
+			mode = tu.Config.DirPerm()
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("failed to set permissions on %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
+// ValidateFiles checks if input and output files have names in the correct format ({number}.in or {number}.out,
+// unless other patterns are configured),
 // ensures each file has a unique number, and validates that there is an equal count of input and output files.
 // Also ensures there is a single description file with a .pdf extension.
 func (tu *TaskUtils) ValidateFiles(files map[string][]byte) error {
@@ -126,9 +209,9 @@ func (tu *TaskUtils) ValidateFiles(files map[string][]byte) error {
 	outputFiles := make(map[int]bool)
 	hasDescription := false
 
-	// Define regex patterns to match "{number}.in" or "{number}.out"
-	inputPattern := regexp.MustCompile(`^(\d+)\.in$`)
-	outputPattern := regexp.MustCompile(`^(\d+)\.out$`)
+	// Get the regex patterns matching the input and output files, "{number}.in" and "{number}.out" by default
+	inputPattern := tu.InputPattern()
+	outputPattern := tu.OutputPattern()
 
 	for fileName := range files {
 		baseName := filepath.Base(fileName)
@@ -137,7 +220,7 @@ func (tu *TaskUtils) ValidateFiles(files map[string][]byte) error {
 		if strings.HasPrefix(fileName, "src/input/") {
 			matches := inputPattern.FindStringSubmatch(baseName)
 			if matches == nil {
-				return fmt.Errorf("input file %s does not match the required format {number}.in", baseName)
+				return fmt.Errorf("input file %s does not match the required format %s", baseName, patternFormat(inputPattern, defaultInputPattern, "{number}.in"))
 			}
 
 			number := matches[1]
@@ -154,7 +237,7 @@ func (tu *TaskUtils) ValidateFiles(files map[string][]byte) error {
 		} else if strings.HasPrefix(fileName, "src/output/") { // Validate output files
 			matches := outputPattern.FindStringSubmatch(baseName)
 			if matches == nil {
-				return fmt.Errorf("output file %s does not match the required format {number}.out", baseName)
+				return fmt.Errorf("output file %s does not match the required format %s", baseName, patternFormat(outputPattern, defaultOutputPattern, "{number}.out"))
 			}
 
 			number := matches[1]
@@ -214,7 +297,7 @@ func (tu *TaskUtils) SaveFiles(inputDir, outputDir string, files map[string][]by
 
 		// Save the file with its original name and extension
 		targetFilePath := filepath.Join(targetDir, filepath.Base(fileName))
-		if err := os.WriteFile(targetFilePath, fileContent, 0644); err != nil {
+		if err := os.WriteFile(targetFilePath, fileContent, tu.Config.FilePerm()); err != nil {
 			return fmt.Errorf("failed to save file %s: %v", fileName, err)
 		}
 	}
@@ -252,8 +335,16 @@ func (tu *TaskUtils) IsAllowedFileExtension(extension string) bool {
 // SaveCompileErrorFile saves the compile-error.err file in the output directory
 func (tu *TaskUtils) SaveCompileErrorFile(outputDir string, fileContent []byte) error {
 	filePath := filepath.Join(outputDir, "compile-error.err")
-	if err := os.WriteFile(filePath, fileContent, 0644); err != nil {
+	if err := os.WriteFile(filePath, fileContent, tu.Config.FilePerm()); err != nil {
 		return fmt.Errorf("failed to save compile-error.err: %v", err)
 	}
 	return nil
 }
+
+// patternFormat describes the file name format required by the pattern for error messages.
+func patternFormat(pattern, defaultPattern *regexp.Regexp, defaultFormat string) string {
+	if pattern.String() == defaultPattern.String() {
+		return defaultFormat
+	}
+	return pattern.String()
+}
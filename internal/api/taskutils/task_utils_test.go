@@ -2,6 +2,8 @@ package taskutils
 
 import (
 	"testing"
+
+	"github.com/mini-maxit/file-storage/internal/config"
 )
 
 func TestValidateFiles(t *testing.T) {
@@ -104,3 +106,93 @@ func TestValidateFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateFilesWithCustomPattern(t *testing.T) {
+	tu := NewTaskUtils(&config.Config{
+		InputFilePattern:  `^input(\d+)\.txt$`,
+		OutputFilePattern: `^expected(\d+)\.txt$`,
+	})
+
+	tests := []struct {
+		name          string
+		files         map[string][]byte
+		expectErr     bool
+		expectedError string
+	}{
+		{
+			name: "valid files with the custom pattern",
+			files: map[string][]byte{
+				"src/input/input1.txt":     {},
+				"src/output/expected1.txt": {},
+				"src/input/input2.txt":     {},
+				"src/output/expected2.txt": {},
+				"description.pdf":          {},
+			},
+			expectErr: false,
+		},
+		{
+			name: "default names rejected by the custom pattern",
+			files: map[string][]byte{
+				"src/input/1.in":   {},
+				"src/output/1.out": {},
+				"description.pdf":  {},
+			},
+			expectErr:     true,
+			expectedError: `input file 1.in does not match the required format ^input(\d+)\.txt$`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := tu.ValidateFiles(test.files)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if err.Error() != test.expectedError {
+					t.Errorf("expected error %v but got %v", test.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	t.Run("configured patterns are compiled once", func(t *testing.T) {
+		tu := NewTaskUtils(&config.Config{
+			InputFilePattern:  `^input(\d+)\.txt$`,
+			OutputFilePattern: `^expected(\d+)\.txt$`,
+		})
+
+		if got := tu.InputPattern().String(); got != `^input(\d+)\.txt$` {
+			t.Errorf("expected the configured input pattern, got %s", got)
+		}
+		if got := tu.OutputPattern().String(); got != `^expected(\d+)\.txt$` {
+			t.Errorf("expected the configured output pattern, got %s", got)
+		}
+		if tu.InputPattern() != tu.InputPattern() || tu.OutputPattern() != tu.OutputPattern() {
+			t.Errorf("expected the patterns not to be recompiled")
+		}
+	})
+
+	t.Run("missing patterns fall back to the defaults", func(t *testing.T) {
+		for _, tu := range []*TaskUtils{NewTaskUtils(&config.Config{}), NewTaskUtils(nil), {}} {
+			if got := tu.InputPattern().String(); got != config.DefaultInputFilePattern {
+				t.Errorf("expected the default input pattern, got %s", got)
+			}
+			if got := tu.OutputPattern().String(); got != config.DefaultOutputFilePattern {
+				t.Errorf("expected the default output pattern, got %s", got)
+			}
+		}
+	})
+
+	t.Run("invalid patterns are not replaced silently", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected NewTaskUtils to panic on an invalid pattern")
+			}
+		}()
+		NewTaskUtils(&config.Config{InputFilePattern: `^(`})
+	})
+}
@@ -3,9 +3,14 @@
 package config
 
 import (
+	"compress/gzip"
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -14,10 +19,154 @@ import (
 //   - Port: the port on which the server will run (defaults to "8080").
 //   - RootDirectory: the directory where tasks/files will be stored (defaults to "tasks/").
 //   - AllowedFileTypes: a list of allowed file types for submissions (defaults to ".c, .cpp, .py").
+//   - TasksSubdir: the directory where task directories are stored, relative to RootDirectory
+//     unless absolute (defaults to "tasks").
+//   - DirMode: the permissions of created directories (defaults to 0755).
+//   - FileMode: the permissions of created files (defaults to 0644).
+//   - RateLimit: the number of requests per second allowed per client IP, 0 disables rate limiting (defaults to 0).
+//   - RateBurst: the number of requests a client may send at once before being limited (defaults to 20).
+//   - PreserveFileNames: whether the original file name of a submission is kept and returned instead of
+//     solution.{ext} (defaults to false).
+//   - CompressionLevel: the gzip compression level of the served archives, from 1 (best speed)
+//     to 9 (best compression) (defaults to gzip's default level).
+//   - InputFilePattern: the regular expression task input file names must match, with the file number
+//     captured by the first group (defaults to {number}.in).
+//   - OutputFilePattern: the regular expression task output file names must match, with the file number
+//     captured by the first group (defaults to {number}.out).
+//   - StorageCheck: whether the task directories are verified on startup, one of "off", "warn" (log the
+//     inconsistencies) or "strict" (refuse to start on inconsistencies) (defaults to "off").
+//   - MaxUploadSize: the maximum size in bytes of a request uploading a submission or its outputs (defaults to 10 MB).
+//   - MaxTaskArchiveSize: the maximum size in bytes of a request uploading a task archive (defaults to 50 MB).
+//   - LogFormat: the format of the logs, "console" or "json" (defaults to "console").
+//   - LogLevel: the minimum level of the logs, e.g. "debug", "info" or "warn" (defaults to "info").
+//   - MinFreeDiskSpace: the minimum free disk space in bytes under RootDirectory for the service to report
+//     itself ready, 0 disables the check (defaults to 100 MB).
+//   - ArchiveRoot: the name of the single root folder of all served archives (task files, input/output pairs,
+//     solution packages and submissions), so they can be extracted the same way. If empty, each archive keeps
+//     its own root folder: task{id}Files, none, Task and Submission respectively (defaults to "").
 type Config struct {
-	Port             string
-	RootDirectory    string
-	AllowedFileTypes []string
+	Port               string
+	RootDirectory      string
+	AllowedFileTypes   []string
+	TasksSubdir        string
+	DirMode            os.FileMode
+	FileMode           os.FileMode
+	RateLimit          float64
+	RateBurst          int
+	PreserveFileNames  bool
+	CompressionLevel   int
+	InputFilePattern   string
+	OutputFilePattern  string
+	StorageCheck       string
+	MaxUploadSize      int64
+	MaxTaskArchiveSize int64
+	LogFormat          string
+	LogLevel           string
+	MinFreeDiskSpace   int64
+	ArchiveRoot        string
+}
+
+const (
+	// DefaultTasksSubdir is the default directory for task directories inside RootDirectory.
+	DefaultTasksSubdir = "tasks"
+	// DefaultDirMode is the default permission of created directories.
+	DefaultDirMode os.FileMode = 0755
+	// DefaultFileMode is the default permission of created files.
+	DefaultFileMode os.FileMode = 0644
+	// DefaultRateLimit is the default number of requests per second allowed per client IP, 0 disables rate limiting.
+	DefaultRateLimit = 0
+	// DefaultRateBurst is the default number of requests a client may send at once.
+	DefaultRateBurst = 20
+	// DefaultInputFilePattern matches the default {number}.in input file names.
+	DefaultInputFilePattern = `^(\d+)\.in$`
+	// DefaultOutputFilePattern matches the default {number}.out output file names.
+	DefaultOutputFilePattern = `^(\d+)\.out$`
+	// DefaultMaxUploadSize is the default maximum size of a request uploading a submission or its outputs.
+	DefaultMaxUploadSize int64 = 10 << 20 // 10 MB
+	// DefaultMaxTaskArchiveSize is the default maximum size of a request uploading a task archive.
+	DefaultMaxTaskArchiveSize int64 = 50 << 20 // 50 MB
+	// DefaultLogLevel is the default minimum level of the logs.
+	DefaultLogLevel = "info"
+	// DefaultMinFreeDiskSpace is the default minimum free disk space for the service to report itself ready.
+	DefaultMinFreeDiskSpace int64 = 100 << 20 // 100 MB
+)
+
+// Log formats, see Config.LogFormat.
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// Storage check modes, see Config.StorageCheck.
+const (
+	StorageCheckOff    = "off"
+	StorageCheckWarn   = "warn"
+	StorageCheckStrict = "strict"
+)
+
+// DirPerm returns the permissions used for created directories, falling back to DefaultDirMode if unset.
+func (c *Config) DirPerm() os.FileMode {
+	if c.DirMode == 0 {
+		return DefaultDirMode
+	}
+	return c.DirMode
+}
+
+// FilePerm returns the permissions used for created files, falling back to DefaultFileMode if unset.
+func (c *Config) FilePerm() os.FileMode {
+	if c.FileMode == 0 {
+		return DefaultFileMode
+	}
+	return c.FileMode
+}
+
+// UploadLimit returns the maximum size of a request uploading a submission or its outputs,
+// falling back to DefaultMaxUploadSize if unset.
+func (c *Config) UploadLimit() int64 {
+	if c.MaxUploadSize <= 0 {
+		return DefaultMaxUploadSize
+	}
+	return c.MaxUploadSize
+}
+
+// TaskArchiveLimit returns the maximum size of a request uploading a task archive,
+// falling back to DefaultMaxTaskArchiveSize if unset.
+func (c *Config) TaskArchiveLimit() int64 {
+	if c.MaxTaskArchiveSize <= 0 {
+		return DefaultMaxTaskArchiveSize
+	}
+	return c.MaxTaskArchiveSize
+}
+
+// GzipLevel returns the gzip compression level used for archives, falling back to gzip.DefaultCompression
+// if unset or out of range.
+func (c *Config) GzipLevel() int {
+	if c.CompressionLevel < gzip.BestSpeed || c.CompressionLevel > gzip.BestCompression {
+		return gzip.DefaultCompression
+	}
+	return c.CompressionLevel
+}
+
+// Level returns the configured log level, falling back to logrus.InfoLevel if unset or invalid.
+func (c *Config) Level() logrus.Level {
+	level, err := logrus.ParseLevel(c.LogLevel)
+	if err != nil {
+		return logrus.InfoLevel
+	}
+	return level
+}
+
+// TasksDirectory returns the directory where task directories are stored.
+// A relative TasksSubdir is resolved against RootDirectory, an absolute one is used as is.
+func (c *Config) TasksDirectory() string {
+	tasksSubdir := c.TasksSubdir
+	if tasksSubdir == "" {
+		tasksSubdir = DefaultTasksSubdir
+	}
+	if filepath.IsAbs(tasksSubdir) {
+		return filepath.Clean(tasksSubdir)
+	}
+	return filepath.Join(c.RootDirectory, tasksSubdir)
 }
 
 // NewConfig loads the application's configuration from environment variables or sets defaults
@@ -50,9 +199,173 @@ func NewConfig() *Config {
 		}
 	}
 
+	tasksSubdir := os.Getenv("TASKS_SUBDIR")
+	if tasksSubdir == "" {
+		tasksSubdir = DefaultTasksSubdir
+	}
+
+	// Load directory and file permissions given as octal numbers (e.g. 0750)
+	dirMode := parseFileMode("DIR_MODE", DefaultDirMode)
+	fileMode := parseFileMode("FILE_MODE", DefaultFileMode)
+
+	rateLimit := float64(DefaultRateLimit)
+	if rateLimitEnv := os.Getenv("RATE_LIMIT"); rateLimitEnv != "" {
+		parsed, err := strconv.ParseFloat(rateLimitEnv, 64)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid RATE_LIMIT %q, using default %d", rateLimitEnv, DefaultRateLimit)
+		} else {
+			rateLimit = parsed
+		}
+	}
+
+	rateBurst := DefaultRateBurst
+	if rateBurstEnv := os.Getenv("RATE_BURST"); rateBurstEnv != "" {
+		parsed, err := strconv.Atoi(rateBurstEnv)
+		if err != nil || parsed < 1 {
+			log.Printf("Invalid RATE_BURST %q, using default %d", rateBurstEnv, DefaultRateBurst)
+		} else {
+			rateBurst = parsed
+		}
+	}
+
+	// Keep the original submission file names, needed e.g. for Java where the class name must match the file name
+	preserveFileNames := false
+	if preserveFileNamesEnv := os.Getenv("PRESERVE_FILE_NAMES"); preserveFileNamesEnv != "" {
+		parsed, err := strconv.ParseBool(preserveFileNamesEnv)
+		if err != nil {
+			log.Printf("Invalid PRESERVE_FILE_NAMES %q, using default false", preserveFileNamesEnv)
+		} else {
+			preserveFileNames = parsed
+		}
+	}
+
+	// Load the gzip compression level, trading CPU time for archive size
+	compressionLevel := gzip.DefaultCompression
+	if compressionLevelEnv := os.Getenv("COMPRESSION_LEVEL"); compressionLevelEnv != "" {
+		parsed, err := strconv.Atoi(compressionLevelEnv)
+		if err != nil || parsed < gzip.BestSpeed || parsed > gzip.BestCompression {
+			log.Printf("Invalid COMPRESSION_LEVEL %q, using the default compression level", compressionLevelEnv)
+		} else {
+			compressionLevel = parsed
+		}
+	}
+
+	// Load the naming patterns of the task input and output files
+	inputFilePattern := parseFilePattern("INPUT_FILE_PATTERN", DefaultInputFilePattern)
+	outputFilePattern := parseFilePattern("OUTPUT_FILE_PATTERN", DefaultOutputFilePattern)
+
+	storageCheck := strings.ToLower(os.Getenv("STORAGE_CHECK"))
+	switch storageCheck {
+	case StorageCheckOff, StorageCheckWarn, StorageCheckStrict:
+	case "":
+		storageCheck = StorageCheckOff
+	default:
+		log.Printf("Invalid STORAGE_CHECK %q, using default %s", storageCheck, StorageCheckOff)
+		storageCheck = StorageCheckOff
+	}
+
+	// Load the maximum request sizes in bytes
+	maxUploadSize := parseSize("MAX_UPLOAD_SIZE", DefaultMaxUploadSize)
+	maxTaskArchiveSize := parseSize("MAX_TASK_ARCHIVE_SIZE", DefaultMaxTaskArchiveSize)
+
+	// Load the minimum free disk space, 0 disables the check
+	minFreeDiskSpace := int64(0)
+	if os.Getenv("MIN_FREE_DISK_SPACE") != "0" {
+		minFreeDiskSpace = parseSize("MIN_FREE_DISK_SPACE", DefaultMinFreeDiskSpace)
+	}
+
+	logFormat := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	switch logFormat {
+	case LogFormatConsole, LogFormatJSON:
+	case "":
+		logFormat = LogFormatConsole
+	default:
+		log.Printf("Invalid LOG_FORMAT %q, using default %s", logFormat, LogFormatConsole)
+		logFormat = LogFormatConsole
+	}
+
+	// Load the root folder name of the served archives, it must be a single path element
+	archiveRoot := strings.TrimSpace(os.Getenv("ARCHIVE_ROOT"))
+	if archiveRoot == "." || archiveRoot == ".." || strings.ContainsAny(archiveRoot, `/\`) {
+		log.Printf("Invalid ARCHIVE_ROOT %q, keeping the default archive layouts", archiveRoot)
+		archiveRoot = ""
+	}
+
+	logLevel := strings.ToLower(os.Getenv("LOG_LEVEL"))
+	if logLevel == "" {
+		logLevel = DefaultLogLevel
+	} else if _, err := logrus.ParseLevel(logLevel); err != nil {
+		log.Printf("Invalid LOG_LEVEL %q, using default %s", logLevel, DefaultLogLevel)
+		logLevel = DefaultLogLevel
+	}
+
 	return &Config{
-		Port:             port,
-		RootDirectory:    rootDirectory,
-		AllowedFileTypes: allowedFileTypes,
+		Port:               port,
+		RootDirectory:      rootDirectory,
+		AllowedFileTypes:   allowedFileTypes,
+		TasksSubdir:        tasksSubdir,
+		DirMode:            dirMode,
+		FileMode:           fileMode,
+		RateLimit:          rateLimit,
+		RateBurst:          rateBurst,
+		PreserveFileNames:  preserveFileNames,
+		CompressionLevel:   compressionLevel,
+		InputFilePattern:   inputFilePattern,
+		OutputFilePattern:  outputFilePattern,
+		StorageCheck:       storageCheck,
+		MaxUploadSize:      maxUploadSize,
+		MaxTaskArchiveSize: maxTaskArchiveSize,
+		LogFormat:          logFormat,
+		LogLevel:           logLevel,
+		MinFreeDiskSpace:   minFreeDiskSpace,
+		ArchiveRoot:        archiveRoot,
+	}
+}
+
+// parseFileMode reads an octal permission from the given environment variable,
+// returning defaultMode if it is not set or invalid.
+func parseFileMode(key string, defaultMode os.FileMode) os.FileMode {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultMode
+	}
+
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		log.Printf("Invalid %s %q, using default %#o", key, value, defaultMode)
+		return defaultMode
+	}
+	return os.FileMode(mode)
+}
+
+// parseFilePattern reads a file name pattern from the given environment variable, returning defaultPattern
+// if it is not set, is not a valid regular expression or has no capture group for the file number.
+func parseFilePattern(key string, defaultPattern string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultPattern
+	}
+
+	re, err := regexp.Compile(value)
+	if err != nil || re.NumSubexp() < 1 {
+		log.Printf("Invalid %s %q, using default %s", key, value, defaultPattern)
+		return defaultPattern
+	}
+	return value
+}
+
+// parseSize reads a positive size in bytes from the given environment variable,
+// returning defaultSize if it is not set or invalid.
+func parseSize(key string, defaultSize int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultSize
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		log.Printf("Invalid %s %q, using default %d", key, value, defaultSize)
+		return defaultSize
 	}
+	return size
 }
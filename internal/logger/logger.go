@@ -0,0 +1,25 @@
+// Package logger configures the application logger based on the configuration.
+package logger
+
+import (
+	"github.com/mini-maxit/file-storage/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// InitializeLogger configures the standard logrus logger used across the application.
+func InitializeLogger(cfg *config.Config) {
+	configure(logrus.StandardLogger(), cfg)
+}
+
+// configure sets the level and the output format of the given logger: JSON for log ingestion pipelines,
+// or colored console output otherwise.
+func configure(l *logrus.Logger, cfg *config.Config) {
+	l.SetLevel(cfg.Level())
+
+	switch cfg.LogFormat {
+	case config.LogFormatJSON:
+		l.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		l.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	}
+}
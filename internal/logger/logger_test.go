@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mini-maxit/file-storage/internal/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	// Subtest for the JSON log format
+	t.Run("should emit JSON logs when configured", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logrus.New()
+		l.SetOutput(&buf)
+		configure(l, &config.Config{LogFormat: config.LogFormatJSON})
+
+		l.WithField("taskID", 1).Info("task created")
+
+		var entry map[string]interface{}
+		err := json.Unmarshal(buf.Bytes(), &entry)
+		assert.NoError(t, err, "expected the log line to be valid JSON")
+		assert.Equal(t, "task created", entry["msg"], "expected the message in the JSON log")
+		assert.EqualValues(t, 1, entry["taskID"], "expected the fields in the JSON log")
+	})
+
+	// Subtest for the default console log format
+	t.Run("should emit console logs by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := logrus.New()
+		l.SetOutput(&buf)
+		configure(l, &config.Config{})
+
+		l.Info("task created")
+
+		assert.Contains(t, buf.String(), `msg="task created"`, "expected a console log line")
+		assert.False(t, json.Valid(buf.Bytes()), "expected the log line not to be JSON")
+	})
+}
+
+func TestConfigureLevel(t *testing.T) {
+	newLogger := func(level string) (*logrus.Logger, *bytes.Buffer) {
+		var buf bytes.Buffer
+		l := logrus.New()
+		l.SetOutput(&buf)
+		configure(l, &config.Config{LogLevel: level})
+		return l, &buf
+	}
+
+	// Subtest for the info level
+	t.Run("should suppress debug logs at info level", func(t *testing.T) {
+		l, buf := newLogger("info")
+		l.Debug("debug message")
+		assert.Empty(t, buf.String(), "expected debug logs to be suppressed")
+	})
+
+	// Subtest for the debug level
+	t.Run("should show debug logs at debug level", func(t *testing.T) {
+		l, buf := newLogger("debug")
+		l.Debug("debug message")
+		assert.Contains(t, buf.String(), "debug message", "expected debug logs to be shown")
+	})
+
+	// Subtest for an invalid level
+	t.Run("should fall back to info level for an invalid level", func(t *testing.T) {
+		l, _ := newLogger("verbose")
+		assert.Equal(t, logrus.InfoLevel, l.GetLevel(), "expected the info level")
+	})
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"errors"
 	"fmt"
@@ -12,6 +14,8 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/ulikunitz/xz"
 )
 
 // FileSize returns size of file
@@ -37,25 +41,89 @@ func RemoveDirectory(path string) {
 	}
 }
 
-// DecompressArchive decompresses archive (either .zip or .tar.gzip) to the given newPath
+// DecompressArchive decompresses archive (.zip, .tar.gz, .tar.bz2 or .tar.xz) to the given newPath.
+// The archive type is taken from the file suffix or, if the suffix is not recognized, from the file content.
 func DecompressArchive(archivePath string, newPath string) error {
-	if strings.HasSuffix(archivePath, ".gz") {
+	switch detectArchiveType(archivePath) {
+	case archiveTypeGzip:
 		err := DecompressGzip(archivePath, newPath)
 		if err != nil {
-			return fmt.Errorf("failed to uncompress directory (gzip): %v", err)
+			return fmt.Errorf("failed to uncompress directory (gzip): %w", err)
+		}
+	case archiveTypeBzip2:
+		err := DecompressBzip2(archivePath, newPath)
+		if err != nil {
+			return fmt.Errorf("failed to uncompress directory (bzip2): %w", err)
 		}
-	} else if strings.HasSuffix(archivePath, ".zip") {
+	case archiveTypeXz:
+		err := DecompressXz(archivePath, newPath)
+		if err != nil {
+			return fmt.Errorf("failed to uncompress directory (xz): %w", err)
+		}
+	case archiveTypeZip:
 		err := DecompressZip(archivePath, newPath)
 		if err != nil {
-			return fmt.Errorf("failed to uncompress directory (zip): %v", err)
+			return fmt.Errorf("failed to uncompress directory (zip): %w", err)
 		}
-	} else {
+	default:
 		return fmt.Errorf("unsupported archive type: %s", archivePath)
 	}
 
 	return nil
 }
 
+const (
+	archiveTypeUnknown = ""
+	archiveTypeGzip    = "gzip"
+	archiveTypeBzip2   = "bzip2"
+	archiveTypeXz      = "xz"
+	archiveTypeZip     = "zip"
+)
+
+// archiveMagicNumbers maps the leading bytes of supported archive types to their type
+var archiveMagicNumbers = []struct {
+	magic       []byte
+	archiveType string
+}{
+	{[]byte{0x1f, 0x8b}, archiveTypeGzip},
+	{[]byte("BZh"), archiveTypeBzip2},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, archiveTypeXz},
+	{[]byte("PK\x03\x04"), archiveTypeZip},
+}
+
+// detectArchiveType determines the archive type from the suffix of archivePath, falling back to
+// sniffing the first bytes of the file. It returns archiveTypeUnknown if the type cannot be determined.
+func detectArchiveType(archivePath string) string {
+	switch {
+	case strings.HasSuffix(archivePath, ".gz") || strings.HasSuffix(archivePath, ".tgz"):
+		return archiveTypeGzip
+	case strings.HasSuffix(archivePath, ".bz2") || strings.HasSuffix(archivePath, ".tbz2"):
+		return archiveTypeBzip2
+	case strings.HasSuffix(archivePath, ".xz") || strings.HasSuffix(archivePath, ".txz"):
+		return archiveTypeXz
+	case strings.HasSuffix(archivePath, ".zip"):
+		return archiveTypeZip
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return archiveTypeUnknown
+	}
+	defer CloseIO(file)
+
+	header := make([]byte, 6)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return archiveTypeUnknown
+	}
+	for _, m := range archiveMagicNumbers {
+		if bytes.HasPrefix(header[:n], m.magic) {
+			return m.archiveType
+		}
+	}
+	return archiveTypeUnknown
+}
+
 // DecompressGzip decompresses a Gzip archive from archivePath to a new directory in the newPath
 func DecompressGzip(archivePath string, newPath string) error {
 	file, err := os.Open(archivePath)
@@ -70,7 +138,54 @@ func DecompressGzip(archivePath string, newPath string) error {
 	}
 	defer CloseIO(uncompressedStream)
 
-	tarReader := tar.NewReader(uncompressedStream)
+	return extractTar(uncompressedStream, newPath)
+}
+
+// DecompressBzip2 decompresses a Bzip2 archive from archivePath to a new directory in the newPath
+func DecompressBzip2(archivePath string, newPath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(file)
+
+	return extractTar(bzip2.NewReader(file), newPath)
+}
+
+// DecompressXz decompresses a Xz archive from archivePath to a new directory in the newPath
+func DecompressXz(archivePath string, newPath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(file)
+
+	uncompressedStream, err := xz.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	return extractTar(uncompressedStream, newPath)
+}
+
+// maxDecompressedSize is the maximum total size of the files extracted from a single archive,
+// protecting the disk from decompression bombs.
+var maxDecompressedSize int64 = 1 << 30 // 1 GB
+
+var (
+	// ErrUnsafeArchiveEntry is returned when an archive entry would be extracted outside the target directory.
+	ErrUnsafeArchiveEntry = errors.New("archive entry path is outside the extraction directory")
+	// ErrUnsupportedArchiveEntry is returned for archive entries other than regular files and directories,
+	// e.g. symbolic or hard links.
+	ErrUnsupportedArchiveEntry = errors.New("unsupported archive entry type")
+	// ErrArchiveTooLarge is returned when the extracted files exceed the maximum decompressed size.
+	ErrArchiveTooLarge = errors.New("decompressed archive is too large")
+)
+
+// extractTar extracts an uncompressed tar stream to a new directory in the newPath
+func extractTar(r io.Reader, newPath string) error {
+	tarReader := tar.NewReader(r)
+	remaining := maxDecompressedSize
 
 	for {
 		header, err := tarReader.Next()
@@ -81,37 +196,30 @@ func DecompressGzip(archivePath string, newPath string) error {
 			return err
 		}
 
+		targetPath, err := extractionPath(newPath, header.Name)
+		if err != nil {
+			return err
+		}
+
 		switch header.Typeflag {
 		case tar.TypeDir:
-			dirPath := path.Join(newPath, header.Name)
-			if err := os.MkdirAll(dirPath, 0755); err != nil {
+			if err := os.MkdirAll(targetPath, 0755); err != nil {
 				return err
 			}
 
 		case tar.TypeReg:
-			filePath := path.Join(newPath, header.Name)
-			if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
-				return err
-			}
-
-			outFile, err := os.Create(filePath)
-			if err != nil {
-				return err
-			}
-			defer CloseIO(outFile)
-
-			if _, err := io.Copy(outFile, tarReader); err != nil {
+			if err := extractFile(targetPath, tarReader, &remaining); err != nil {
 				return err
 			}
 
 		default:
-			return errors.New("unsupported file type")
+			return fmt.Errorf("%w: %s", ErrUnsupportedArchiveEntry, header.Name)
 		}
 	}
 	return nil
 }
 
-// DecompressZip decompresses a Gzip archive from archivePath to a new directory in the newPath
+// DecompressZip decompresses a Zip archive from archivePath to a new directory in the newPath
 func DecompressZip(archivePath string, newPath string) error {
 	r, err := zip.OpenReader(archivePath)
 	if err != nil {
@@ -119,34 +227,333 @@ func DecompressZip(archivePath string, newPath string) error {
 	}
 	defer CloseIO(r)
 
+	remaining := maxDecompressedSize
 	for _, f := range r.File {
-		filePath := filepath.Join(newPath, f.Name)
+		targetPath, err := extractionPath(newPath, f.Name)
+		if err != nil {
+			return err
+		}
 
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(filePath, 0755); err != nil {
-				return err
-			}
-		} else {
-			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(targetPath, 0755); err != nil {
 				return err
 			}
 
+		case mode.IsRegular():
 			inFile, err := f.Open()
 			if err != nil {
 				return err
 			}
-			defer CloseIO(inFile)
-
-			outFile, err := os.Create(filePath)
+			err = extractFile(targetPath, inFile, &remaining)
+			CloseIO(inFile)
 			if err != nil {
 				return err
 			}
-			defer CloseIO(outFile)
 
-			if _, err := io.Copy(outFile, inFile); err != nil {
-				return err
-			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedArchiveEntry, f.Name)
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// extractionPath returns the path the archive entry name is extracted to inside newPath.
+// It rejects absolute names and names escaping newPath, e.g. "../evil".
+func extractionPath(newPath string, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchiveEntry, name)
+	}
+
+	targetPath := filepath.Join(newPath, filepath.Clean(filepath.FromSlash(name)))
+	relPath, err := filepath.Rel(newPath, targetPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchiveEntry, name)
+	}
+	return targetPath, nil
+}
+
+// extractFile writes the content of a single archive entry to targetPath, creating its parent directories.
+// remaining holds the number of bytes that may still be extracted from the archive and is decreased accordingly.
+func extractFile(targetPath string, r io.Reader, remaining *int64) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+
+	outFile, err := os.Create(targetPath)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(outFile)
+
+	written, err := io.CopyN(outFile, r, *remaining+1)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if written > *remaining {
+		return ErrArchiveTooLarge
+	}
+	*remaining -= written
+	return nil
+}
+
+// ArchiveEntry describes a single entry of an archive.
+type ArchiveEntry struct {
+	Name  string
+	Size  int64 // uncompressed size in bytes
+	Mode  os.FileMode
+	IsDir bool
+}
+
+// ListArchive returns the entries of an archive (.zip, .tar.gz, .tar.bz2 or .tar.xz) without extracting it.
+// The archive type is detected the same way as in DecompressArchive.
+func ListArchive(archivePath string) ([]ArchiveEntry, error) {
+	var entries []ArchiveEntry
+	var err error
+
+	archiveType := detectArchiveType(archivePath)
+	switch archiveType {
+	case archiveTypeGzip:
+		entries, err = ListGzip(archivePath)
+	case archiveTypeBzip2:
+		entries, err = ListBzip2(archivePath)
+	case archiveTypeXz:
+		entries, err = ListXz(archivePath)
+	case archiveTypeZip:
+		entries, err = ListZip(archivePath)
+	default:
+		return nil, fmt.Errorf("unsupported archive type: %s", archivePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive (%s): %w", archiveType, err)
+	}
+
+	return entries, nil
+}
+
+// ListGzip returns the entries of a Gzip archive from archivePath
+func ListGzip(archivePath string) ([]ArchiveEntry, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	uncompressedStream, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(uncompressedStream)
+
+	return listTar(uncompressedStream)
+}
+
+// ListBzip2 returns the entries of a Bzip2 archive from archivePath
+func ListBzip2(archivePath string) ([]ArchiveEntry, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	return listTar(bzip2.NewReader(file))
+}
+
+// ListXz returns the entries of a Xz archive from archivePath
+func ListXz(archivePath string) ([]ArchiveEntry, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	uncompressedStream, err := xz.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return listTar(uncompressedStream)
+}
+
+// listTar returns the entries of an uncompressed tar stream
+func listTar(r io.Reader) ([]ArchiveEntry, error) {
+	tarReader := tar.NewReader(r)
+
+	var entries []ArchiveEntry
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info := header.FileInfo()
+		entries = append(entries, ArchiveEntry{
+			Name:  header.Name,
+			Size:  header.Size,
+			Mode:  info.Mode(),
+			IsDir: info.IsDir(),
+		})
+	}
+	return entries, nil
+}
+
+// ListZip returns the entries of a Zip archive from archivePath
+func ListZip(archivePath string) ([]ArchiveEntry, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(r)
+
+	entries := make([]ArchiveEntry, 0, len(r.File))
+	for _, f := range r.File {
+		info := f.FileInfo()
+		entries = append(entries, ArchiveEntry{
+			Name:  f.Name,
+			Size:  int64(f.UncompressedSize64),
+			Mode:  info.Mode(),
+			IsDir: info.IsDir(),
+		})
+	}
+	return entries, nil
+}
+
+// maxExtractedFileSize is the maximum size of a single file extracted into memory by ExtractFile.
+var maxExtractedFileSize int64 = 50 * 1024 * 1024 // 50 MB
+
+var (
+	// ErrArchiveEntryNotFound is returned when the requested entry is not present in the archive.
+	ErrArchiveEntryNotFound = errors.New("archive entry not found")
+	// ErrArchiveEntryTooLarge is returned when the requested entry exceeds the maximum extracted file size.
+	ErrArchiveEntryTooLarge = errors.New("archive entry is too large")
+)
+
+// ExtractFile reads a single named entry of an archive (.zip, .tar.gz, .tar.bz2 or .tar.xz) into memory
+// without extracting the rest of the archive. The archive type is detected the same way as in DecompressArchive.
+func ExtractFile(archivePath string, entryName string) ([]byte, error) {
+	var content []byte
+	var err error
+
+	archiveType := detectArchiveType(archivePath)
+	switch archiveType {
+	case archiveTypeGzip:
+		content, err = ExtractFileGzip(archivePath, entryName)
+	case archiveTypeBzip2:
+		content, err = ExtractFileBzip2(archivePath, entryName)
+	case archiveTypeXz:
+		content, err = ExtractFileXz(archivePath, entryName)
+	case archiveTypeZip:
+		content, err = ExtractFileZip(archivePath, entryName)
+	default:
+		return nil, fmt.Errorf("unsupported archive type: %s", archivePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract file (%s): %w", archiveType, err)
+	}
+
+	return content, nil
+}
+
+// ExtractFileGzip reads a single named entry of a Gzip archive from archivePath into memory
+func ExtractFileGzip(archivePath string, entryName string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	uncompressedStream, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(uncompressedStream)
+
+	return extractFileTar(uncompressedStream, entryName)
+}
+
+// ExtractFileBzip2 reads a single named entry of a Bzip2 archive from archivePath into memory
+func ExtractFileBzip2(archivePath string, entryName string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	return extractFileTar(bzip2.NewReader(file), entryName)
+}
+
+// ExtractFileXz reads a single named entry of a Xz archive from archivePath into memory
+func ExtractFileXz(archivePath string, entryName string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(file)
+
+	uncompressedStream, err := xz.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractFileTar(uncompressedStream, entryName)
+}
+
+// extractFileTar reads a single named entry of an uncompressed tar stream into memory
+func extractFileTar(r io.Reader, entryName string) ([]byte, error) {
+	tarReader := tar.NewReader(r)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && path.Clean(header.Name) == path.Clean(entryName) {
+			return readLimited(tarReader)
+		}
+	}
+	return nil, ErrArchiveEntryNotFound
+}
+
+// ExtractFileZip reads a single named entry of a Zip archive from archivePath into memory
+func ExtractFileZip(archivePath string, entryName string) ([]byte, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIO(r)
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || path.Clean(f.Name) != path.Clean(entryName) {
+			continue
+		}
+
+		inFile, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer CloseIO(inFile)
+
+		return readLimited(inFile)
+	}
+	return nil, ErrArchiveEntryNotFound
+}
+
+// readLimited reads the whole reader, failing if it holds more than maxExtractedFileSize bytes
+func readLimited(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxExtractedFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxExtractedFileSize {
+		return nil, ErrArchiveEntryTooLarge
+	}
+	return content, nil
+}
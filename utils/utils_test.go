@@ -4,10 +4,14 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/ulikunitz/xz"
 )
 
 // setupTestFiles creates sample .zip and .tar.gz files for testing
@@ -26,6 +30,16 @@ func setupTestFiles() error {
 		return fmt.Errorf("failed to create sample tar.gz: %w", err)
 	}
 
+	// Create sample tar.bz2 file
+	if err := createSampleTarBz2("testdata/test.tar.bz2"); err != nil {
+		return fmt.Errorf("failed to create sample tar.bz2: %w", err)
+	}
+
+	// Create sample tar.xz file
+	if err := createSampleTarXz("testdata/test.tar.xz"); err != nil {
+		return fmt.Errorf("failed to create sample tar.xz: %w", err)
+	}
+
 	return nil
 }
 
@@ -99,6 +113,60 @@ func createSampleTarGz(filePath string) error {
 	return nil
 }
 
+// sampleTarBz2 is a tar.bz2 archive with the same files as the other samples.
+// The standard library cannot write bzip2, so the archive is embedded.
+const sampleTarBz2 = "QlpoOTFBWSZTWfMAzrsAAJF7gMqAIABAAX+ABIBjZB5ASAggAHBjAATAAEwKoomRhNA0aGJ6lNVyqU3yDCyIhCcfq1yOjBW4oQkTGpfdksRFjsqIZq0KmjqnOZi5YzWqKzw2MfM09tSts3N0n5Ro/KMnREH8XckU4UJDzAM67A=="
+
+// createSampleTarBz2 writes the embedded sample tar.bz2 archive
+func createSampleTarBz2(filePath string) error {
+	content, err := base64.StdEncoding.DecodeString(sampleTarBz2)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, content, 0644)
+}
+
+// createSampleTarXz creates a sample tar.xz archive with a few test files
+func createSampleTarXz(filePath string) error {
+	outFile, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(outFile)
+
+	xzWriter, err := xz.NewWriter(outFile)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(xzWriter)
+
+	tarWriter := tar.NewWriter(xzWriter)
+	defer CloseIO(tarWriter)
+
+	files := []struct {
+		Name, Body string
+	}{
+		{"file1.txt", "This is file1"},
+		{"file2.txt", "This is file2"},
+	}
+
+	for _, file := range files {
+		hdr := &tar.Header{
+			Name: file.Name,
+			Mode: 0600,
+			Size: int64(len(file.Body)),
+		}
+		if err := tarWriter.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write([]byte(file.Body)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func TestDecompressArchive(t *testing.T) {
 	// Setup test files
 	if err := setupTestFiles(); err != nil {
@@ -129,6 +197,18 @@ func TestDecompressArchive(t *testing.T) {
 			newPath:     "testdata/output_tar_gz",
 			expectedErr: "",
 		},
+		{
+			name:        "Valid TAR.BZ2 Archive",
+			archivePath: "testdata/test.tar.bz2",
+			newPath:     "testdata/output_tar_bz2",
+			expectedErr: "",
+		},
+		{
+			name:        "Valid TAR.XZ Archive",
+			archivePath: "testdata/test.tar.xz",
+			newPath:     "testdata/output_tar_xz",
+			expectedErr: "",
+		},
 		{
 			name:        "Unsupported File Type",
 			archivePath: "testdata/test.txt",
@@ -170,6 +250,10 @@ func TestDecompressArchive(t *testing.T) {
 				if _, err := os.Stat(tt.newPath); os.IsNotExist(err) {
 					t.Errorf("expected output directory '%s' to exist, but it does not", tt.newPath)
 				}
+				content, err := os.ReadFile(tt.newPath + "/file1.txt")
+				if err != nil || string(content) != "This is file1" {
+					t.Errorf("expected file1.txt to be extracted with its content, got '%s' (%v)", content, err)
+				}
 			}
 
 			// Clean up test output directory after each test
@@ -180,3 +264,336 @@ func TestDecompressArchive(t *testing.T) {
 		})
 	}
 }
+
+func TestListArchive(t *testing.T) {
+	// Setup test files
+	if err := setupTestFiles(); err != nil {
+		t.Fatalf("failed to set up test files: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll("testdata")
+	}() // Cleanup test files after tests
+
+	tests := []struct {
+		name        string
+		archivePath string
+		expectedErr string
+	}{
+		{
+			name:        "Valid ZIP Archive",
+			archivePath: "testdata/test.zip",
+		},
+		{
+			name:        "Valid TAR.GZ Archive",
+			archivePath: "testdata/test.tar.gz",
+		},
+		{
+			name:        "Valid TAR.BZ2 Archive",
+			archivePath: "testdata/test.tar.bz2",
+		},
+		{
+			name:        "Valid TAR.XZ Archive",
+			archivePath: "testdata/test.tar.xz",
+		},
+		{
+			name:        "Unsupported File Type",
+			archivePath: "testdata/test.txt",
+			expectedErr: "unsupported archive type",
+		},
+		{
+			name:        "Non-existent Archive Path",
+			archivePath: "testdata/nonexistent.zip",
+			expectedErr: "failed to list archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ListArchive(tt.archivePath)
+
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing '%s', got '%v'", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// All sample archives contain the same two files
+			expected := map[string]int64{
+				"file1.txt": int64(len("This is file1")),
+				"file2.txt": int64(len("This is file2")),
+			}
+			if len(entries) != len(expected) {
+				t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+			}
+			for _, entry := range entries {
+				size, ok := expected[entry.Name]
+				if !ok {
+					t.Errorf("unexpected entry '%s'", entry.Name)
+					continue
+				}
+				if entry.Size != size {
+					t.Errorf("expected size %d for '%s', got %d", size, entry.Name, entry.Size)
+				}
+				if entry.IsDir {
+					t.Errorf("expected '%s' not to be a directory", entry.Name)
+				}
+			}
+
+			// Verify nothing was extracted next to the archive
+			if _, err := os.Stat("testdata/file1.txt"); !os.IsNotExist(err) {
+				t.Errorf("expected the archive not to be extracted")
+			}
+		})
+	}
+
+	t.Run("Wrapped Error", func(t *testing.T) {
+		_, err := ListArchive("testdata/nonexistent.zip")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected the error to wrap os.ErrNotExist, got '%v'", err)
+		}
+	})
+}
+
+func TestExtractFile(t *testing.T) {
+	// Setup test files
+	if err := setupTestFiles(); err != nil {
+		t.Fatalf("failed to set up test files: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll("testdata")
+	}() // Cleanup test files after tests
+
+	for _, archivePath := range []string{"testdata/test.zip", "testdata/test.tar.gz", "testdata/test.tar.bz2", "testdata/test.tar.xz"} {
+		t.Run("Extract single file from "+archivePath, func(t *testing.T) {
+			content, err := ExtractFile(archivePath, "file2.txt")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(content) != "This is file2" {
+				t.Errorf("expected content 'This is file2', got '%s'", content)
+			}
+		})
+
+		t.Run("Missing entry in "+archivePath, func(t *testing.T) {
+			_, err := ExtractFile(archivePath, "missing.txt")
+			if !errors.Is(err, ErrArchiveEntryNotFound) {
+				t.Errorf("expected ErrArchiveEntryNotFound, got '%v'", err)
+			}
+		})
+	}
+
+	t.Run("Extract single file from an archive without a recognizable suffix", func(t *testing.T) {
+		content, err := os.ReadFile("testdata/test.tar.xz")
+		if err != nil {
+			t.Fatalf("failed to read sample archive: %v", err)
+		}
+		if err := os.WriteFile("testdata/archive.upload", content, 0644); err != nil {
+			t.Fatalf("failed to write archive copy: %v", err)
+		}
+
+		extracted, err := ExtractFile("testdata/archive.upload", "file1.txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(extracted) != "This is file1" {
+			t.Errorf("expected content 'This is file1', got '%s'", extracted)
+		}
+	})
+
+	for _, archivePath := range []string{"testdata/test.zip", "testdata/test.tar.gz"} {
+		t.Run("Entry exceeding the size limit in "+archivePath, func(t *testing.T) {
+			defer func(size int64) {
+				maxExtractedFileSize = size
+			}(maxExtractedFileSize)
+			maxExtractedFileSize = 5
+
+			_, err := ExtractFile(archivePath, "file1.txt")
+			if !errors.Is(err, ErrArchiveEntryTooLarge) {
+				t.Errorf("expected ErrArchiveEntryTooLarge, got '%v'", err)
+			}
+		})
+	}
+
+	t.Run("Unsupported File Type", func(t *testing.T) {
+		_, err := ExtractFile("testdata/test.txt", "file1.txt")
+		if err == nil || !strings.Contains(err.Error(), "unsupported archive type") {
+			t.Errorf("expected error containing 'unsupported archive type', got '%v'", err)
+		}
+	})
+}
+
+func TestDecompressArchiveContentSniffing(t *testing.T) {
+	// Setup test files
+	if err := setupTestFiles(); err != nil {
+		t.Fatalf("failed to set up test files: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll("testdata")
+	}() // Cleanup test files after tests
+
+	// Copy each sample archive to a file without a recognizable suffix
+	for _, archivePath := range []string{"testdata/test.zip", "testdata/test.tar.gz", "testdata/test.tar.bz2", "testdata/test.tar.xz"} {
+		t.Run("Detect type of "+archivePath, func(t *testing.T) {
+			content, err := os.ReadFile(archivePath)
+			if err != nil {
+				t.Fatalf("failed to read sample archive: %v", err)
+			}
+			if err := os.WriteFile("testdata/archive.upload", content, 0644); err != nil {
+				t.Fatalf("failed to write archive copy: %v", err)
+			}
+			defer func() {
+				_ = os.RemoveAll("testdata/output_sniffed")
+			}()
+
+			if err := DecompressArchive("testdata/archive.upload", "testdata/output_sniffed"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Stat("testdata/output_sniffed/file2.txt"); err != nil {
+				t.Errorf("expected file2.txt to be extracted: %v", err)
+			}
+		})
+	}
+}
+
+// createTarGz creates a tar.gz archive with the given headers, writing the given content for regular files
+func createTarGz(filePath string, headers []*tar.Header, content string) error {
+	outFile, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer CloseIO(outFile)
+
+	gzipWriter := gzip.NewWriter(outFile)
+	defer CloseIO(gzipWriter)
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer CloseIO(tarWriter)
+
+	for _, hdr := range headers {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(content))
+		}
+		if err := tarWriter.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tarWriter.Write([]byte(content)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestDecompressArchiveUnsafeEntries(t *testing.T) {
+	if err := os.MkdirAll("testdata", 0755); err != nil {
+		t.Fatalf("failed to create testdata directory: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll("testdata")
+	}() // Cleanup test files after tests
+
+	tests := []struct {
+		name        string
+		headers     []*tar.Header
+		expectedErr error
+	}{
+		{
+			name:        "Parent Directory Entry",
+			headers:     []*tar.Header{{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644}},
+			expectedErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:        "Nested Parent Directory Entry",
+			headers:     []*tar.Header{{Name: "Task/../../evil", Typeflag: tar.TypeReg, Mode: 0644}},
+			expectedErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:        "Absolute Entry",
+			headers:     []*tar.Header{{Name: "/tmp/evil", Typeflag: tar.TypeReg, Mode: 0644}},
+			expectedErr: ErrUnsafeArchiveEntry,
+		},
+		{
+			name:        "Symlink Entry",
+			headers:     []*tar.Header{{Name: "Task/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd", Mode: 0777}},
+			expectedErr: ErrUnsupportedArchiveEntry,
+		},
+		{
+			name:        "Hardlink Entry",
+			headers:     []*tar.Header{{Name: "Task/link", Typeflag: tar.TypeLink, Linkname: "/etc/passwd", Mode: 0644}},
+			expectedErr: ErrUnsupportedArchiveEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := createTarGz("testdata/unsafe.tar.gz", tt.headers, "evil"); err != nil {
+				t.Fatalf("failed to create archive: %v", err)
+			}
+			defer func() {
+				_ = os.RemoveAll("testdata/output_unsafe")
+			}()
+
+			err := DecompressArchive("testdata/unsafe.tar.gz", "testdata/output_unsafe")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error '%v', got '%v'", tt.expectedErr, err)
+			}
+			if _, err := os.Stat("testdata/evil"); !os.IsNotExist(err) {
+				t.Errorf("expected no file to be written outside the extraction directory")
+			}
+		})
+	}
+
+	t.Run("Parent Directory Entry in ZIP", func(t *testing.T) {
+		outFile, err := os.Create("testdata/unsafe.zip")
+		if err != nil {
+			t.Fatalf("failed to create archive: %v", err)
+		}
+		zipWriter := zip.NewWriter(outFile)
+		f, err := zipWriter.Create("../evil")
+		if err != nil {
+			t.Fatalf("failed to create zip entry: %v", err)
+		}
+		_, _ = f.Write([]byte("evil"))
+		CloseIO(zipWriter)
+		CloseIO(outFile)
+		defer func() {
+			_ = os.RemoveAll("testdata/output_unsafe")
+		}()
+
+		err = DecompressArchive("testdata/unsafe.zip", "testdata/output_unsafe")
+		if !errors.Is(err, ErrUnsafeArchiveEntry) {
+			t.Errorf("expected error '%v', got '%v'", ErrUnsafeArchiveEntry, err)
+		}
+		if _, err := os.Stat("testdata/evil"); !os.IsNotExist(err) {
+			t.Errorf("expected no file to be written outside the extraction directory")
+		}
+	})
+
+	t.Run("Archive Exceeding the Decompressed Size", func(t *testing.T) {
+		defer func(size int64) {
+			maxDecompressedSize = size
+		}(maxDecompressedSize)
+		maxDecompressedSize = 20
+
+		headers := []*tar.Header{
+			{Name: "Task/file1.txt", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "Task/file2.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		}
+		if err := createTarGz("testdata/large.tar.gz", headers, "This is file1"); err != nil {
+			t.Fatalf("failed to create archive: %v", err)
+		}
+		defer func() {
+			_ = os.RemoveAll("testdata/output_large")
+		}()
+
+		err := DecompressArchive("testdata/large.tar.gz", "testdata/output_large")
+		if !errors.Is(err, ErrArchiveTooLarge) {
+			t.Errorf("expected error '%v', got '%v'", ErrArchiveTooLarge, err)
+		}
+	})
+}